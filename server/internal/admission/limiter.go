@@ -0,0 +1,68 @@
+// Package admission caps how many booking transactions run against one event
+// at a time, so a flash sale queues in memory instead of as row lock waiters
+// in Postgres.
+package admission
+
+import "sync"
+
+// Limiter is a per-event counting semaphore that never blocks: a caller over
+// the limit is turned away at once and expected to retry. It only sees this
+// instance's bookings, so the effective cap across the fleet is the limit
+// times the number of instances. A nil *Limiter admits everything.
+type Limiter struct {
+	limit int
+
+	mu       sync.Mutex
+	inFlight map[int]int
+}
+
+// NewLimiter admits up to perEvent concurrent bookings per event; a limit of
+// 0 or less disables admission control and returns nil
+func NewLimiter(perEvent int) *Limiter {
+	if perEvent <= 0 {
+		return nil
+	}
+	return &Limiter{
+		limit:    perEvent,
+		inFlight: make(map[int]int),
+	}
+}
+
+// TryAcquire takes a slot for eventID. When it succeeds the caller must call
+// release once its transaction is done; release is safe to call more than once.
+func (l *Limiter) TryAcquire(eventID int) (release func(), ok bool) {
+	if l == nil {
+		return func() {}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[eventID] >= l.limit {
+		return nil, false
+	}
+	l.inFlight[eventID]++
+
+	var once sync.Once
+	return func() { once.Do(func() { l.release(eventID) }) }, true
+}
+
+// InFlight is the number of bookings currently admitted for eventID
+func (l *Limiter) InFlight(eventID int) int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight[eventID]
+}
+
+func (l *Limiter) release(eventID int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Drop idle events so the map only holds events with bookings in flight
+	if l.inFlight[eventID] <= 1 {
+		delete(l.inFlight, eventID)
+		return
+	}
+	l.inFlight[eventID]--
+}
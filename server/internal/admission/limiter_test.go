@@ -0,0 +1,120 @@
+package admission
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLimiterRejectsOverLimit(t *testing.T) {
+	l := NewLimiter(2)
+
+	release1, ok1 := l.TryAcquire(1)
+	release2, ok2 := l.TryAcquire(1)
+	if !ok1 || !ok2 {
+		t.Fatal("bookings within the limit were rejected")
+	}
+
+	// The third booking is the one the handler answers with 503 high_demand
+	if release, ok := l.TryAcquire(1); ok || release != nil {
+		t.Fatal("booking over the limit was admitted")
+	}
+	if got := l.InFlight(1); got != 2 {
+		t.Errorf("InFlight = %d, want 2", got)
+	}
+
+	// Other events have slots of their own
+	if _, ok := l.TryAcquire(2); !ok {
+		t.Error("booking for another event was rejected")
+	}
+
+	release1()
+	if _, ok := l.TryAcquire(1); !ok {
+		t.Error("booking was rejected after a slot was released")
+	}
+	release2()
+}
+
+func TestLimiterReleaseIsIdempotent(t *testing.T) {
+	l := NewLimiter(1)
+
+	release, _ := l.TryAcquire(1)
+	other, ok := l.TryAcquire(1)
+	if ok || other != nil {
+		t.Fatal("second booking admitted over a limit of 1")
+	}
+
+	release()
+	release()
+	if got := l.InFlight(1); got != 0 {
+		t.Errorf("InFlight = %d after releasing twice, want 0", got)
+	}
+
+	// A double release must not have freed a slot it never held
+	_, ok1 := l.TryAcquire(1)
+	_, ok2 := l.TryAcquire(1)
+	if !ok1 || ok2 {
+		t.Errorf("after a double release: admitted %v, %v; want true, false", ok1, ok2)
+	}
+}
+
+func TestNilLimiterAdmitsEverything(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		l := NewLimiter(limit)
+		if l != nil {
+			t.Fatalf("NewLimiter(%d) = %v, want nil", limit, l)
+		}
+		for i := 0; i < 100; i++ {
+			release, ok := l.TryAcquire(1)
+			if !ok {
+				t.Fatal("nil limiter rejected a booking")
+			}
+			release()
+		}
+		if got := l.InFlight(1); got != 0 {
+			t.Errorf("InFlight = %d, want 0", got)
+		}
+	}
+}
+
+// TestLimiterConcurrent has many goroutines take and release slots for one
+// event and checks no more than the limit ever hold one at once
+func TestLimiterConcurrent(t *testing.T) {
+	const limit = 5
+	l := NewLimiter(limit)
+
+	var holding, maxHolding, admitted, rejected atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, ok := l.TryAcquire(7)
+			if !ok {
+				rejected.Add(1)
+				return
+			}
+			admitted.Add(1)
+			n := holding.Add(1)
+			for {
+				prev := maxHolding.Load()
+				if n <= prev || maxHolding.CompareAndSwap(prev, n) {
+					break
+				}
+			}
+			holding.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if got := maxHolding.Load(); got > limit {
+		t.Errorf("%d bookings held a slot at once, limit %d", got, limit)
+	}
+	if admitted.Load()+rejected.Load() != 200 || admitted.Load() == 0 {
+		t.Errorf("admitted %d, rejected %d", admitted.Load(), rejected.Load())
+	}
+	if got := l.InFlight(7); got != 0 {
+		t.Errorf("InFlight = %d after every booking released, want 0", got)
+	}
+}
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+const listVersionKey = "events:list:version"
+
+// availableCountTTL is short on purpose: the count changes with every lock and
+// booking, and the cache only absorbs bursts of polling from the same page
+const availableCountTTL = 2 * time.Second
+
+// seatMapImageTTL keeps a rendered seat map long enough to serve a burst of
+// email or dashboard loads without re-rendering each one
+const seatMapImageTTL = 5 * time.Second
+
+// EventCache caches event reads in Redis. A nil *EventCache is valid and
+// behaves as an always-empty cache, so callers don't need to check whether
+// Redis is configured. Cache failures are logged and treated as misses.
+type EventCache struct {
+	client *redis.Client
+	ttl    time.Duration
+	logger *logrus.Logger
+}
+
+// NewEventCache returns nil when no Redis client is available
+func NewEventCache(client *redis.Client, ttl time.Duration, logger *logrus.Logger) *EventCache {
+	if client == nil || ttl <= 0 {
+		return nil
+	}
+	return &EventCache{
+		client: client,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// GetEvent returns a cached event, if present
+func (c *EventCache) GetEvent(ctx context.Context, eventID int) (*models.Event, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	var event models.Event
+	if !c.get(ctx, eventKey(eventID), &event) {
+		return nil, false
+	}
+	event.Status = event.StatusAt(time.Now())
+	return &event, true
+}
+
+// SetEvent caches a single event
+func (c *EventCache) SetEvent(ctx context.Context, event *models.Event) {
+	if c == nil {
+		return
+	}
+	c.set(ctx, eventKey(event.ID), event)
+}
+
+// GetEvents returns a cached page of events, if present
+func (c *EventCache) GetEvents(ctx context.Context, limit, offset int) ([]*models.Event, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	key, ok := c.listKey(ctx, limit, offset)
+	if !ok {
+		return nil, false
+	}
+
+	var events []*models.Event
+	if !c.get(ctx, key, &events) {
+		return nil, false
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		event.Status = event.StatusAt(now)
+	}
+	return events, true
+}
+
+// SetEvents caches a page of events
+func (c *EventCache) SetEvents(ctx context.Context, limit, offset int, events []*models.Event) {
+	if c == nil {
+		return
+	}
+
+	key, ok := c.listKey(ctx, limit, offset)
+	if !ok {
+		return
+	}
+	c.set(ctx, key, events)
+}
+
+// GetAvailableCount returns a cached count of an event's available seats, if present
+func (c *EventCache) GetAvailableCount(ctx context.Context, eventID int) (*models.AvailableCount, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	var count models.AvailableCount
+	if !c.get(ctx, availableCountKey(eventID), &count) {
+		return nil, false
+	}
+	return &count, true
+}
+
+// SetAvailableCount caches an event's available seat count for a couple of seconds
+func (c *EventCache) SetAvailableCount(ctx context.Context, count *models.AvailableCount) {
+	if c == nil {
+		return
+	}
+	c.setWithTTL(ctx, availableCountKey(count.EventID), count, availableCountTTL)
+}
+
+// GetSeatMapImage returns a cached seat map PNG rendered at scale, if present
+func (c *EventCache) GetSeatMapImage(ctx context.Context, eventID, scale int) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	var image []byte
+	if !c.get(ctx, seatMapImageKey(eventID, scale), &image) {
+		return nil, false
+	}
+	return image, true
+}
+
+// SetSeatMapImage caches a rendered seat map PNG for a few seconds
+func (c *EventCache) SetSeatMapImage(ctx context.Context, eventID, scale int, image []byte) {
+	if c == nil {
+		return
+	}
+	c.setWithTTL(ctx, seatMapImageKey(eventID, scale), image, seatMapImageTTL)
+}
+
+// Invalidate drops the cached event and every cached event page
+func (c *EventCache) Invalidate(ctx context.Context, eventID int) {
+	if c == nil {
+		return
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.Del(ctx, eventKey(eventID))
+	pipe.Incr(ctx, listVersionKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.WithError(err).WithField("event_id", eventID).Warn("Failed to invalidate event cache")
+	}
+}
+
+func (c *EventCache) get(ctx context.Context, key string, dest interface{}) bool {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			c.logger.WithError(err).WithField("key", key).Warn("Failed to read from event cache")
+		}
+		return false
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to decode cached event data")
+		return false
+	}
+	return true
+}
+
+func (c *EventCache) set(ctx context.Context, key string, value interface{}) {
+	c.setWithTTL(ctx, key, value, c.ttl)
+}
+
+func (c *EventCache) setWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to encode event data for cache")
+		return
+	}
+
+	if err := c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to write to event cache")
+	}
+}
+
+// listKey embeds the list version so that a single INCR invalidates every page
+func (c *EventCache) listKey(ctx context.Context, limit, offset int) (string, bool) {
+	version, err := c.client.Get(ctx, listVersionKey).Int64()
+	if err != nil && err != redis.Nil {
+		c.logger.WithError(err).Warn("Failed to read event list cache version")
+		return "", false
+	}
+	return fmt.Sprintf("events:list:v%d:%d:%d", version, limit, offset), true
+}
+
+func eventKey(eventID int) string {
+	return fmt.Sprintf("events:%d", eventID)
+}
+
+func availableCountKey(eventID int) string {
+	return fmt.Sprintf("events:%d:available", eventID)
+}
+
+func seatMapImageKey(eventID, scale int) string {
+	return fmt.Sprintf("events:%d:seatmap:%d", eventID, scale)
+}
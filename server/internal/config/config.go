@@ -1,17 +1,24 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Redis    RedisConfig
 	App      AppConfig
 }
 
@@ -20,6 +27,27 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// RequestTimeout cancels a request's context; EventWriteTimeout replaces it for event creation
+	RequestTimeout    time.Duration
+	EventWriteTimeout time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may run after SIGTERM
+	ShutdownTimeout time.Duration
+	// TLS is served in-process (with HTTP/2) when both files are set
+	TLSCertFile string
+	TLSKeyFile  string
+	// TrustedProxies are the proxy IPs/CIDRs whose X-Forwarded-For is believed
+	// when resolving the client IP; empty trusts no proxy
+	TrustedProxies []string
+	// Gzip compresses responses of at least GzipMinSize bytes for clients that
+	// accept it, except under the GzipExcludePaths prefixes
+	GzipEnabled      bool
+	GzipMinSize      int
+	GzipExcludePaths []string
+}
+
+// TLSEnabled reports whether the server terminates TLS itself
+func (s *ServerConfig) TLSEnabled() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
 }
 
 type DatabaseConfig struct {
@@ -32,18 +60,103 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	RunMigrations   bool // apply embedded migrations on startup
+	// StatementTimeout makes Postgres cancel any statement running longer; 0 disables
+	StatementTimeout time.Duration
+	// A read replica is used when ReplicaHost is set; the other replica
+	// settings default to the primary's
+	ReplicaHost     string
+	ReplicaPort     string
+	ReplicaUser     string
+	ReplicaPassword string
+	ReplicaDBName   string
+}
+
+// Replica returns the connection settings for the read replica, with the pool
+// and timeout settings of the primary, or nil when no replica is configured
+func (d *DatabaseConfig) Replica() *DatabaseConfig {
+	if d.ReplicaHost == "" {
+		return nil
+	}
+
+	replica := *d
+	replica.Host = d.ReplicaHost
+	if d.ReplicaPort != "" {
+		replica.Port = d.ReplicaPort
+	}
+	if d.ReplicaUser != "" {
+		replica.User = d.ReplicaUser
+	}
+	if d.ReplicaPassword != "" {
+		replica.Password = d.ReplicaPassword
+	}
+	if d.ReplicaDBName != "" {
+		replica.DBName = d.ReplicaDBName
+	}
+	return &replica
+}
+
+type RedisConfig struct {
+	URL string // e.g. redis://:password@localhost:6379/0; Redis features are disabled when empty
 }
 
 type AppConfig struct {
-	LogLevel     string
-	RateLimitRPS int
-	LockTimeout  time.Duration
-	MaxRetries   int
-	RetryDelay   time.Duration
+	LogLevel         string
+	LogFormat        string // "json" (default) or "text" for local development
+	RateLimitRPS     int
+	RateLimitBackend string // "memory" (per instance) or "redis" (shared across instances)
+	// RateLimitExempt lists client networks that bypass rate limiting, e.g. monitoring
+	RateLimitExempt []netip.Prefix
+	LockTimeout     time.Duration
+	MaxRetries      int
+	RetryDelay      time.Duration
+	// SlowQueryThreshold logs database calls and transactions slower than this; 0 disables
+	SlowQueryThreshold time.Duration
+	// LatencyReportInterval logs per-route p50/p95/p99 latency this often; 0 disables
+	LatencyReportInterval time.Duration
 	// Seat and booking configuration
 	SeatLockDuration  time.Duration // How long seats remain locked during selection
+	MaxHoldDuration   time.Duration // Longest a seat lock may be kept alive by refreshes; 0 means unlimited
+	LockExpiryGrace   time.Duration // How long past expiry a seat lock survives cleanup, so a booking sent at the last moment still finds it
 	BookingExpiration time.Duration // How long users have to complete payment
-	CleanupInterval   time.Duration // How often to run expired lock cleanup
+	// Booking references are BookingRefPrefix plus a nanosecond timestamp, or
+	// BookingRefLength random characters from BookingRefCharset when it is set
+	BookingRefPrefix  string
+	BookingRefLength  int
+	BookingRefCharset string
+	// ServiceFee is added to every booking, and TaxRate is charged on the
+	// tickets after discount plus that fee
+	ServiceFee         models.ServiceFee
+	TaxRate            models.Rate
+	CleanupInterval    time.Duration // How often to run expired lock cleanup
+	EventCacheTTL      time.Duration // How long event reads stay cached in Redis; kept short so seat counts stay fresh
+	DefaultCurrency    string        // ISO 4217 code for events created without a currency
+	RequireSession     bool          // Reject lock/hold requests without a session instead of issuing a session cookie
+	MaxLocksPerSession int           // Most seats one session may have locked per event; 0 means unlimited
+	// MaxConcurrentBookings caps booking transactions running at once per event on this instance; 0 means unlimited
+	MaxConcurrentBookings int
+	// Pagination configuration
+	DefaultPageSize       int  // Page size for list endpoints when ?limit is absent or invalid
+	MaxPageSize           int  // Largest ?limit accepted by list endpoints
+	DefaultTicketPageSize int  // Page size for /events/:id/tickets
+	MaxTicketPageSize     int  // Largest ?limit accepted by /events/:id/tickets
+	DefaultSeatListSize   int  // Page size for /events/:id/tickets/all, which backs the seat grid
+	MaxSeatListSize       int  // Largest ?limit accepted by /events/:id/tickets/all
+	StrictPagination      bool // Reject out-of-range page and limit with 400 instead of correcting them
+	// Admin and maintenance configuration
+	AdminAPIKey        string        // Bearer token required by /admin routes; admin API is disabled when empty
+	GateAPIKey         string        // Bearer token for gate staff checking tickets in; the admin key is accepted too
+	ReconcileOnCleanup bool          // Also reconcile available_tickets on every cleanup tick
+	ReconcileInterval  time.Duration // How often the availability reconcile job runs; 0 disables it
+	// OrphanCleanupInterval is how often reserved tickets without a pending booking are released; 0 disables it
+	OrphanCleanupInterval time.Duration
+	EnablePprof           bool // Mount /debug/pprof behind admin auth
+	// StrictStartup refuses to start when the startup self-check finds the
+	// schema or configuration out of step; otherwise findings are only logged
+	StrictStartup bool
+	// AllowPastEvents lets requests with the admin key create events that have
+	// already started, for importing historical data
+	AllowPastEvents bool
 }
 
 func Load() (*Config, error) {
@@ -54,39 +167,210 @@ func Load() (*Config, error) {
 
 	config := &Config{
 		Server: ServerConfig{
-			Port:         getEnv("PORT", "8080"),
-			ReadTimeout:  getDuration("READ_TIMEOUT", 15*time.Second),
-			WriteTimeout: getDuration("WRITE_TIMEOUT", 15*time.Second),
-			IdleTimeout:  getDuration("IDLE_TIMEOUT", 60*time.Second),
+			Port:              getEnv("PORT", "8080"),
+			ReadTimeout:       getDuration("READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:      getDuration("WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:       getDuration("IDLE_TIMEOUT", 60*time.Second),
+			ShutdownTimeout:   getDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			RequestTimeout:    getDuration("REQUEST_TIMEOUT", 30*time.Second),
+			EventWriteTimeout: getDuration("EVENT_WRITE_TIMEOUT", 2*time.Minute),
+			TLSCertFile:       getEnv("TLS_CERT_FILE", ""),
+			TLSKeyFile:        getEnv("TLS_KEY_FILE", ""),
+			TrustedProxies:    getEnvList("TRUSTED_PROXIES"),
+			GzipEnabled:       getEnvBool("GZIP_ENABLED", true),
+			GzipMinSize:       getEnvInt("GZIP_MIN_SIZE", 1024),
+			GzipExcludePaths:  getEnvList("GZIP_EXCLUDE_PATHS"),
 		},
 		Database: DatabaseConfig{
-			Host:            getEnv("DB_HOST", "localhost"),
-			Port:            getEnv("DB_PORT", "5432"),
-			User:            getEnv("DB_USER", "postgres"),
-			Password:        getEnv("DB_PASSWORD", "password"),
-			DBName:          getEnv("DB_NAME", "ticket_booking"),
-			SSLMode:         getEnv("DB_SSL_MODE", "disable"),
-			MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime: getDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			Host:             getEnv("DB_HOST", "localhost"),
+			Port:             getEnv("DB_PORT", "5432"),
+			User:             getEnv("DB_USER", "postgres"),
+			Password:         getEnv("DB_PASSWORD", "password"),
+			DBName:           getEnv("DB_NAME", "ticket_booking"),
+			SSLMode:          getEnv("DB_SSL_MODE", "disable"),
+			MaxOpenConns:     getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:     getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime:  getDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			RunMigrations:    getEnvBool("RUN_MIGRATIONS", false),
+			StatementTimeout: getDuration("DB_STATEMENT_TIMEOUT", 10*time.Second),
+			ReplicaHost:      getEnv("DB_REPLICA_HOST", ""),
+			ReplicaPort:      getEnv("DB_REPLICA_PORT", ""),
+			ReplicaUser:      getEnv("DB_REPLICA_USER", ""),
+			ReplicaPassword:  getEnv("DB_REPLICA_PASSWORD", ""),
+			ReplicaDBName:    getEnv("DB_REPLICA_NAME", ""),
+		},
+		Redis: RedisConfig{
+			URL: getEnv("REDIS_URL", ""),
 		},
 
 		App: AppConfig{
-			LogLevel:     getEnv("LOG_LEVEL", "info"),
-			RateLimitRPS: getEnvInt("RATE_LIMIT_RPS", 100),
-			LockTimeout:  getDuration("LOCK_TIMEOUT", 30*time.Second),
-			MaxRetries:   getEnvInt("MAX_RETRIES", 3),
-			RetryDelay:   getDuration("RETRY_DELAY", 100*time.Millisecond),
+			LogLevel:              getEnv("LOG_LEVEL", "info"),
+			LogFormat:             getEnv("LOG_FORMAT", "json"),
+			RateLimitRPS:          getEnvInt("RATE_LIMIT_RPS", 100),
+			RateLimitBackend:      getEnv("RATE_LIMIT_BACKEND", "memory"),
+			LockTimeout:           getDuration("LOCK_TIMEOUT", 30*time.Second),
+			MaxRetries:            getEnvInt("MAX_RETRIES", 3),
+			RetryDelay:            getDuration("RETRY_DELAY", 100*time.Millisecond),
+			SlowQueryThreshold:    getDuration("SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+			LatencyReportInterval: getDuration("LATENCY_REPORT_INTERVAL", 1*time.Minute),
 			// Seat and booking configuration with defaults
-			SeatLockDuration:  getDuration("SEAT_LOCK_DURATION", 3*time.Minute),
-			BookingExpiration: getDuration("BOOKING_EXPIRATION", 15*time.Minute),
-			CleanupInterval:   getDuration("CLEANUP_INTERVAL", 1*time.Minute),
+			SeatLockDuration:      getDuration("SEAT_LOCK_DURATION", 3*time.Minute),
+			MaxHoldDuration:       getDuration("MAX_HOLD_DURATION", 15*time.Minute),
+			LockExpiryGrace:       getDuration("LOCK_EXPIRY_GRACE", 5*time.Second),
+			BookingExpiration:     getDuration("BOOKING_EXPIRATION", 15*time.Minute),
+			BookingRefPrefix:      getEnv("BOOKING_REF_PREFIX", "BK"),
+			BookingRefLength:      getEnvInt("BOOKING_REF_LENGTH", 0),
+			BookingRefCharset:     getEnv("BOOKING_REF_CHARSET", "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"),
+			CleanupInterval:       getDuration("CLEANUP_INTERVAL", 1*time.Minute),
+			EventCacheTTL:         getDuration("EVENT_CACHE_TTL", 2*time.Second),
+			DefaultCurrency:       strings.ToUpper(getEnv("DEFAULT_CURRENCY", models.DefaultCurrency)),
+			RequireSession:        getEnvBool("REQUIRE_SESSION", false),
+			MaxLocksPerSession:    getEnvInt("MAX_LOCKS_PER_SESSION", 10),
+			MaxConcurrentBookings: getEnvInt("MAX_CONCURRENT_BOOKINGS_PER_EVENT", 20),
+			// Pagination configuration
+			DefaultPageSize:       getEnvInt("DEFAULT_PAGE_SIZE", 20),
+			MaxPageSize:           getEnvInt("MAX_PAGE_SIZE", 100),
+			DefaultTicketPageSize: getEnvInt("DEFAULT_TICKET_PAGE_SIZE", 50),
+			MaxTicketPageSize:     getEnvInt("MAX_TICKET_PAGE_SIZE", 100),
+			DefaultSeatListSize:   getEnvInt("DEFAULT_SEAT_LIST_SIZE", 200),
+			MaxSeatListSize:       getEnvInt("MAX_SEAT_LIST_SIZE", 500),
+			StrictPagination:      getEnvBool("STRICT_PAGINATION", false),
+			// Admin and maintenance configuration
+			AdminAPIKey:           getEnv("ADMIN_API_KEY", ""),
+			AllowPastEvents:       getEnvBool("ALLOW_PAST_EVENTS", false),
+			GateAPIKey:            getEnv("GATE_API_KEY", ""),
+			ReconcileOnCleanup:    getEnvBool("RECONCILE_ON_CLEANUP", false),
+			ReconcileInterval:     getDuration("RECONCILE_INTERVAL", 5*time.Minute),
+			OrphanCleanupInterval: getDuration("ORPHAN_CLEANUP_INTERVAL", 10*time.Minute),
+			EnablePprof:           getEnvBool("ENABLE_PPROF", false),
+			StrictStartup:         getEnvBool("STRICT_STARTUP", false),
 		},
 	}
 
+	exempt, err := parsePrefixes(getEnvList("RATE_LIMIT_EXEMPT_IPS"))
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_EXEMPT_IPS: %w", err)
+	}
+	config.App.RateLimitExempt = exempt
+
+	if config.App.ServiceFee, err = models.ParseServiceFee(getEnv("SERVICE_FEE", "")); err != nil {
+		return nil, fmt.Errorf("SERVICE_FEE must be an amount per ticket such as 1.50 or a percentage such as 5%%: %w", err)
+	}
+	if config.App.TaxRate, err = models.ParseRate(getEnv("TAX_RATE", "0")); err != nil {
+		return nil, fmt.Errorf("TAX_RATE must be a percentage such as 8.875: %w", err)
+	}
+	if config.App.ServiceFee.Rate > models.MaxRate || config.App.TaxRate > models.MaxRate {
+		return nil, fmt.Errorf("SERVICE_FEE and TAX_RATE cannot exceed 100%%")
+	}
+
+	for _, proxy := range config.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return nil, fmt.Errorf("TRUSTED_PROXIES entry %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	if (config.Server.TLSCertFile == "") != (config.Server.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	if config.Server.GzipMinSize < 0 {
+		return nil, fmt.Errorf("GZIP_MIN_SIZE cannot be negative, got %d", config.Server.GzipMinSize)
+	}
+	for _, path := range config.Server.GzipExcludePaths {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("GZIP_EXCLUDE_PATHS entry %q must start with /", path)
+		}
+	}
+
+	if config.App.LogFormat != "json" && config.App.LogFormat != "text" {
+		return nil, fmt.Errorf("LOG_FORMAT must be json or text, got %q", config.App.LogFormat)
+	}
+
+	if !models.IsCurrencyCode(config.App.DefaultCurrency) {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY must be an ISO 4217 currency code, got %q", config.App.DefaultCurrency)
+	}
+
+	if config.App.MaxLocksPerSession < 0 {
+		return nil, fmt.Errorf("MAX_LOCKS_PER_SESSION cannot be negative, got %d", config.App.MaxLocksPerSession)
+	}
+
+	if err := validateBookingRef(&config.App); err != nil {
+		return nil, err
+	}
+
+	if config.App.MaxConcurrentBookings < 0 {
+		return nil, fmt.Errorf("MAX_CONCURRENT_BOOKINGS_PER_EVENT cannot be negative, got %d", config.App.MaxConcurrentBookings)
+	}
+
+	if config.Database.StatementTimeout < 0 {
+		return nil, fmt.Errorf("DB_STATEMENT_TIMEOUT cannot be negative, got %s", config.Database.StatementTimeout)
+	}
+	// A statement that times out must do so while the request can still
+	// report it, rather than the request being cancelled first
+	if config.Database.StatementTimeout > 0 && config.Server.RequestTimeout > 0 &&
+		config.Database.StatementTimeout >= config.Server.RequestTimeout {
+		return nil, fmt.Errorf("DB_STATEMENT_TIMEOUT (%s) must be shorter than REQUEST_TIMEOUT (%s)",
+			config.Database.StatementTimeout, config.Server.RequestTimeout)
+	}
+
+	if config.App.LatencyReportInterval < 0 {
+		return nil, fmt.Errorf("LATENCY_REPORT_INTERVAL cannot be negative, got %s", config.App.LatencyReportInterval)
+	}
+
+	if config.App.SlowQueryThreshold < 0 {
+		return nil, fmt.Errorf("SLOW_QUERY_THRESHOLD cannot be negative, got %s", config.App.SlowQueryThreshold)
+	}
+
+	if config.App.OrphanCleanupInterval < 0 {
+		return nil, fmt.Errorf("ORPHAN_CLEANUP_INTERVAL cannot be negative, got %s", config.App.OrphanCleanupInterval)
+	}
+
+	if config.App.ReconcileInterval < 0 {
+		return nil, fmt.Errorf("RECONCILE_INTERVAL cannot be negative, got %s", config.App.ReconcileInterval)
+	}
+
+	if config.App.LockExpiryGrace < 0 || config.App.LockExpiryGrace > 30*time.Second {
+		return nil, fmt.Errorf("LOCK_EXPIRY_GRACE must be between 0 and 30s, got %s", config.App.LockExpiryGrace)
+	}
+
+	if config.App.MaxHoldDuration < 0 {
+		return nil, fmt.Errorf("MAX_HOLD_DURATION cannot be negative, got %s", config.App.MaxHoldDuration)
+	}
+
+	if config.App.MaxRetries < 0 || config.App.MaxRetries > 10 {
+		return nil, fmt.Errorf("MAX_RETRIES must be between 0 and 10, got %d", config.App.MaxRetries)
+	}
+	if config.App.RetryDelay < 0 || config.App.RetryDelay > 5*time.Second {
+		return nil, fmt.Errorf("RETRY_DELAY must be between 0 and 5s, got %s", config.App.RetryDelay)
+	}
+
+	if err := validatePageSizes(&config.App); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
+func validatePageSizes(app *AppConfig) error {
+	pairs := []struct {
+		name        string
+		defaultSize int
+		maxSize     int
+	}{
+		{"PAGE_SIZE", app.DefaultPageSize, app.MaxPageSize},
+		{"TICKET_PAGE_SIZE", app.DefaultTicketPageSize, app.MaxTicketPageSize},
+		{"SEAT_LIST_SIZE", app.DefaultSeatListSize, app.MaxSeatListSize},
+	}
+	for _, p := range pairs {
+		if p.defaultSize < 1 || p.defaultSize > p.maxSize {
+			return fmt.Errorf("DEFAULT_%s must be between 1 and MAX_%s (got %d and %d)", p.name, p.name, p.defaultSize, p.maxSize)
+		}
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -94,6 +378,80 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// parsePrefixes parses CIDRs; a bare address is taken as a single-host prefix
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if addr, err := netip.ParseAddr(value); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// getEnvList splits a comma-separated variable, dropping blank entries
+// maxBookingRefLength is the width of bookings.booking_ref
+const maxBookingRefLength = 50
+
+// validateBookingRef checks the booking reference format. References must fit
+// the column and stay safe to put in URLs and emails, so only letters, digits,
+// '-' and '_' are allowed.
+func validateBookingRef(app *AppConfig) error {
+	safe := func(s string, extra string) bool {
+		for _, r := range s {
+			if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune(extra, r)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(app.BookingRefPrefix) > 16 || !safe(app.BookingRefPrefix, "-_") {
+		return fmt.Errorf("BOOKING_REF_PREFIX must be at most 16 letters, digits, '-' or '_', got %q", app.BookingRefPrefix)
+	}
+
+	if app.BookingRefLength == 0 {
+		// A nanosecond timestamp has 19 digits
+		if len(app.BookingRefPrefix)+19 > maxBookingRefLength {
+			return fmt.Errorf("BOOKING_REF_PREFIX %q leaves no room for the timestamp", app.BookingRefPrefix)
+		}
+		return nil
+	}
+
+	if app.BookingRefLength < 6 || len(app.BookingRefPrefix)+app.BookingRefLength > maxBookingRefLength {
+		return fmt.Errorf("BOOKING_REF_LENGTH must be 0 or between 6 and %d with this prefix, got %d",
+			maxBookingRefLength-len(app.BookingRefPrefix), app.BookingRefLength)
+	}
+
+	seen := make(map[rune]bool)
+	for _, r := range app.BookingRefCharset {
+		if seen[r] {
+			return fmt.Errorf("BOOKING_REF_CHARSET repeats %q", r)
+		}
+		seen[r] = true
+	}
+	if len(seen) < 10 || !safe(app.BookingRefCharset, "") {
+		return fmt.Errorf("BOOKING_REF_CHARSET must be at least 10 distinct letters or digits, got %q", app.BookingRefCharset)
+	}
+	return nil
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -103,6 +461,15 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadRetrySettings(t *testing.T) {
+	tests := []struct {
+		retries, delay string
+		ok             bool
+		wantRetries    int
+		wantDelay      time.Duration
+	}{
+		{"0", "0s", true, 0, 0},
+		{"5", "250ms", true, 5, 250 * time.Millisecond},
+		{"10", "5s", true, 10, 5 * time.Second},
+		{"-1", "100ms", false, 0, 0},
+		{"11", "100ms", false, 0, 0},
+		{"3", "-1ms", false, 0, 0},
+		{"3", "6s", false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("MAX_RETRIES", tt.retries)
+		t.Setenv("RETRY_DELAY", tt.delay)
+
+		cfg, err := Load()
+		if (err == nil) != tt.ok {
+			t.Errorf("MAX_RETRIES=%s RETRY_DELAY=%s: err = %v, want ok = %v", tt.retries, tt.delay, err, tt.ok)
+			continue
+		}
+		if tt.ok && (cfg.App.MaxRetries != tt.wantRetries || cfg.App.RetryDelay != tt.wantDelay) {
+			t.Errorf("MAX_RETRIES=%s RETRY_DELAY=%s: loaded %d, %s", tt.retries, tt.delay, cfg.App.MaxRetries, cfg.App.RetryDelay)
+		}
+	}
+}
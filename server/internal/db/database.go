@@ -3,25 +3,74 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 
 	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/requestid"
 )
 
 type DB struct {
 	*sql.DB
 	logger *logrus.Logger
+	// slowQueryThreshold is the duration above which statements and
+	// transactions are logged; 0 disables slow-query logging
+	slowQueryThreshold time.Duration
+	// replica serves reads that tolerate replication lag; nil sends them to this pool
+	replica *DB
 }
 
+// NewConnection connects to the primary and, when one is configured, the read replica
 func NewConnection(cfg *config.DatabaseConfig, logger *logrus.Logger) (*DB, error) {
+	primary, err := openPool(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Database connection established successfully")
+
+	database := &DB{
+		DB:     primary,
+		logger: logger,
+	}
+
+	if replicaCfg := cfg.Replica(); replicaCfg != nil {
+		replica, err := openPool(replicaCfg)
+		if err != nil {
+			primary.Close()
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+		logger.WithField("host", replicaCfg.Host).Info("Read replica connection established successfully")
+		database.replica = &DB{DB: replica, logger: logger}
+	}
+
+	return database, nil
+}
+
+// Wrap adopts a pool opened elsewhere, e.g. by a test from a DSN, without a replica
+func Wrap(pool *sql.DB, logger *logrus.Logger) *DB {
+	return &DB{DB: pool, logger: logger}
+}
+
+// openPool opens and pings a connection pool
+func openPool(cfg *config.DatabaseConfig) (*sql.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
 	)
+	// Applied by the server to every session, so no single statement, e.g. a
+	// FOR UPDATE stuck behind another transaction, can hang indefinitely
+	if cfg.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" options='-c statement_timeout=%d'", cfg.StatementTimeout.Milliseconds())
+	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
@@ -38,24 +87,137 @@ func NewConnection(cfg *config.DatabaseConfig, logger *logrus.Logger) (*DB, erro
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	logger.Info("Database connection established successfully")
+	return db, nil
+}
 
-	return &DB{
-		DB:     db,
-		logger: logger,
-	}, nil
+// Reader returns the pool for reads that can tolerate replication lag: the
+// read replica when one is configured, otherwise the primary. Writes,
+// transactions and FOR UPDATE always use the primary.
+func (db *DB) Reader() *DB {
+	if db.replica != nil {
+		return db.replica
+	}
+	return db
+}
+
+// HasReplica reports whether reads from Reader go to a separate replica
+func (db *DB) HasReplica() bool {
+	return db.replica != nil
+}
+
+// SetSlowQueryThreshold sets the duration above which statements and
+// transactions are logged at warn level; 0 disables slow-query logging
+func (db *DB) SetSlowQueryThreshold(threshold time.Duration) {
+	db.slowQueryThreshold = threshold
+	if db.replica != nil {
+		db.replica.slowQueryThreshold = threshold
+	}
+}
+
+// QueryContext runs a query on the pool, logging it if it is slow
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	db.logIfSlow(ctx, "query", query, start, 1)
+	return rows, err
+}
+
+// QueryRowContext runs a single-row query on the pool, logging it if it is slow
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	db.logIfSlow(ctx, "query", query, start, 1)
+	return row
+}
+
+// ExecContext runs a statement on the pool, logging it if it is slow
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	db.logIfSlow(ctx, "exec", query, start, 1)
+	return result, err
+}
+
+// logIfSlow warns when the work started at start took longer than the slow
+// query threshold. skip is the number of frames between logIfSlow and the
+// function to report as the caller, which is only looked up for slow calls.
+func (db *DB) logIfSlow(ctx context.Context, kind, statement string, start time.Time, skip int) {
+	if db.slowQueryThreshold <= 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < db.slowQueryThreshold {
+		return
+	}
+
+	fields := logrus.Fields{
+		"kind":       kind,
+		"duration":   elapsed,
+		"threshold":  db.slowQueryThreshold,
+		"request_id": requestid.FromContext(ctx),
+		"caller":     caller(skip + 1),
+	}
+	if statement != "" {
+		fields["statement"] = compactStatement(statement)
+	}
+	db.logger.WithFields(fields).Warn("Slow database call")
+}
+
+// caller names the function skip frames above its own caller as
+// "package.Function (file.go:line)"
+func caller(skip int) string {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	name := "unknown"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = fn.Name()
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+	}
+	return fmt.Sprintf("%s (%s:%d)", name, filepath.Base(file), line)
+}
+
+// maxLoggedStatement bounds how much SQL a slow-query entry carries
+const maxLoggedStatement = 300
+
+// compactStatement collapses the indentation of multi-line SQL for logging
+func compactStatement(statement string) string {
+	compact := strings.Join(strings.Fields(statement), " ")
+	if len(compact) > maxLoggedStatement {
+		compact = compact[:maxLoggedStatement] + "..."
+	}
+	return compact
 }
 
 func (db *DB) Close() error {
+	if db.replica != nil {
+		db.logger.Info("Closing read replica connection")
+		if err := db.replica.DB.Close(); err != nil {
+			db.logger.WithError(err).Warn("Failed to close read replica connection")
+		}
+	}
 	db.logger.Info("Closing database connection")
 	return db.DB.Close()
 }
 
-// Transaction wrapper for pessimistic locking
-func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error {
+// Transaction wrapper for pessimistic locking.
+// The transaction is bound to ctx: if the request is cancelled or times out,
+// database/sql rolls it back and releases any FOR UPDATE locks it holds.
+// Callers should keep fn short and must pass the same ctx to every statement
+// so that abandoned requests don't block others waiting on the same rows.
+func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) (err error) {
+	// Statements inside fn run on the transaction, not the pool, so lock waits
+	// show up here as a slow transaction attributed to its caller
+	start := time.Now()
+	defer db.logIfSlow(ctx, "transaction", "", start, 1)
+
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelReadCommitted,
 	})
@@ -68,7 +230,8 @@ func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error
 			tx.Rollback()
 			panic(p)
 		} else if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
+			// ErrTxDone means database/sql already rolled back a cancelled transaction
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
 				db.logger.WithError(rbErr).Error("Failed to rollback transaction")
 			}
 		} else {
@@ -79,11 +242,16 @@ func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error
 	}()
 
 	err = fn(tx)
+	if err == nil && ctx.Err() != nil {
+		// Don't commit work for a request that has already been abandoned
+		err = ctx.Err()
+	}
 	return err
 }
 
-// Retry mechanism for handling deadlocks and temporary failures
-func (db *DB) WithRetry(ctx context.Context, maxRetries int, retryDelay time.Duration, fn func() error) error {
+// Retry mechanism for handling deadlocks and temporary failures. operation
+// names the caller in retry logs, next to the request ID carried by ctx.
+func (db *DB) WithRetry(ctx context.Context, operation string, maxRetries int, retryDelay time.Duration, fn func() error) error {
 	var err error
 	for i := 0; i <= maxRetries; i++ {
 		err = fn()
@@ -97,7 +265,11 @@ func (db *DB) WithRetry(ctx context.Context, maxRetries int, retryDelay time.Dur
 		}
 
 		if i < maxRetries {
-			db.logger.WithError(err).Warnf("Operation failed, retrying in %v (attempt %d/%d)", retryDelay, i+1, maxRetries)
+			db.logger.WithError(err).WithFields(logrus.Fields{
+				"operation":  operation,
+				"request_id": requestid.FromContext(ctx),
+				"attempt":    i + 1,
+			}).Warnf("Operation failed, retrying in %v (attempt %d/%d)", retryDelay, i+1, maxRetries)
 
 			select {
 			case <-ctx.Done():
@@ -111,30 +283,29 @@ func (db *DB) WithRetry(ctx context.Context, maxRetries int, retryDelay time.Dur
 	return fmt.Errorf("operation failed after %d retries: %w", maxRetries, err)
 }
 
+// Retryable PostgreSQL error codes
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+	pqQueryCanceled        = "57014" // e.g. by DB_STATEMENT_TIMEOUT
+)
+
 func isRetryableError(err error) bool {
-	// Check for PostgreSQL error codes that indicate retryable errors
-	// 40001 = serialization_failure
-	// 40P01 = deadlock_detected
-	errStr := err.Error()
-	return contains(errStr, "40001") ||
-		contains(errStr, "40P01") ||
-		contains(errStr, "deadlock") ||
-		contains(errStr, "serialization failure") ||
-		contains(errStr, "connection") ||
-		contains(errStr, "timeout")
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(s == substr || (len(s) > len(substr) &&
-			findSubstring(s, substr)))
-}
-
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
+	// A cancelled or timed out request must not be retried
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqSerializationFailure, pqDeadlockDetected, pqQueryCanceled:
 			return true
 		}
+		return false
 	}
-	return false
+
+	// Connection failures: a bad pooled connection or a network error
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr)
 }
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// testDB is a DB without a pool, which is all WithRetry needs
+func testDB() *DB {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return Wrap(nil, logger)
+}
+
+func TestWithRetryHonoursMaxRetries(t *testing.T) {
+	deadlock := &pq.Error{Code: "40P01", Message: "deadlock detected"}
+
+	for _, maxRetries := range []int{0, 1, 3, 5} {
+		calls := 0
+		err := testDB().WithRetry(context.Background(), "test", maxRetries, time.Millisecond, func() error {
+			calls++
+			return deadlock
+		})
+
+		if calls != maxRetries+1 {
+			t.Errorf("maxRetries %d: fn called %d times, want %d", maxRetries, calls, maxRetries+1)
+		}
+		if !errors.Is(err, deadlock) {
+			t.Errorf("maxRetries %d: err = %v, want it to wrap the last error", maxRetries, err)
+		}
+	}
+}
+
+func TestWithRetryStopsOnSuccess(t *testing.T) {
+	transient := []error{
+		&pq.Error{Code: "40P01", Message: "deadlock detected"},
+		&pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"},
+	}
+
+	for _, failure := range transient {
+		calls := 0
+		err := testDB().WithRetry(context.Background(), "test", 3, time.Millisecond, func() error {
+			calls++
+			if calls < 3 {
+				return failure
+			}
+			return nil
+		})
+
+		if err != nil || calls != 3 {
+			t.Errorf("%v: err = %v after %d calls, want success on the third", failure, err, calls)
+		}
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"statement timeout", &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}, true},
+		{"wrapped deadlock", fmt.Errorf("failed to lock tickets: %w", &pq.Error{Code: "40P01"}), true},
+		{"bad connection", driver.ErrBadConn, true},
+		{"network error", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, true},
+		{"unique violation", &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}, false},
+		{"check violation mentioning timeout", &pq.Error{Code: "23514", Message: `violates check constraint "hold_timeout_positive"`}, false},
+		{"plain error mentioning timeout", errors.New("seat lock timeout must be positive"), false},
+		{"plain error mentioning connection", errors.New("no connection between booking and event"), false},
+		{"context canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetryNonRetryable(t *testing.T) {
+	notRetryable := []error{
+		errors.New("not enough tickets available"),
+		&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"},
+		context.Canceled,
+	}
+
+	for _, want := range notRetryable {
+		calls := 0
+		err := testDB().WithRetry(context.Background(), "test", 3, time.Millisecond, func() error {
+			calls++
+			return want
+		})
+
+		if calls != 1 {
+			t.Errorf("%v: fn called %d times, want 1", want, calls)
+		}
+		if err != want {
+			t.Errorf("err = %v, want %v unwrapped", err, want)
+		}
+	}
+}
+
+func TestWithRetryCancelledBetweenAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := testDB().WithRetry(ctx, "test", 3, time.Hour, func() error {
+		calls++
+		cancel()
+		return &pq.Error{Code: "40P01", Message: "deadlock detected"}
+	})
+
+	if calls != 1 || !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
@@ -0,0 +1,211 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// migrationLockID is the Postgres advisory lock key that serialises migration
+// runs, so several instances starting together don't apply the same version twice
+const migrationLockID = 7_240_331_001
+
+type migration struct {
+	version string
+	name    string
+	file    string
+}
+
+// Migrate applies every NNN_name.up.sql file in migrations that isn't yet
+// recorded in schema_migrations. Each migration runs in its own transaction
+// together with its schema_migrations row, so a failure leaves the database at
+// the last fully applied version and the next start retries from there.
+func (db *DB) Migrate(ctx context.Context, migrations fs.FS) error {
+	pending, err := listMigrations(migrations)
+	if err != nil {
+		return err
+	}
+
+	// Advisory locks are per session, so pin a single connection for the run
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Waiting for another instance's run and building indexes can both outlast
+	// DB_STATEMENT_TIMEOUT, so lift it on this connection until it is returned
+	if _, err := conn.ExecContext(ctx, `SET statement_timeout = 0`); err != nil {
+		return fmt.Errorf("failed to disable statement timeout for migrations: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `RESET statement_timeout`); err != nil {
+			db.logger.WithError(err).Warn("Failed to restore statement timeout after migrations")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			db.logger.WithError(err).Warn("Failed to release migration lock")
+		}
+	}()
+
+	createQuery := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(50) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`
+	if _, err := conn.ExecContext(ctx, createQuery); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, m := range pending {
+		if applied[m.version] {
+			continue
+		}
+
+		script, err := fs.ReadFile(migrations, m.file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", m.file, err)
+		}
+
+		if err := applyMigration(ctx, conn, m, string(script)); err != nil {
+			return err
+		}
+
+		db.logger.WithFields(logrus.Fields{
+			"version": m.version,
+			"name":    m.name,
+		}).Info("Applied database migration")
+		count++
+	}
+
+	if count == 0 {
+		db.logger.Info("Database schema is up to date")
+	} else {
+		db.logger.WithField("applied", count).Info("Database migrations complete")
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration, script string) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.version, err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %s_%s failed: %w", m.version, m.name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.version, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.version, err)
+	}
+	return nil
+}
+
+// PendingMigrations lists the up migrations in migrations, as NNN_name, that
+// schema_migrations has no record of. ok is false when schema_migrations does
+// not exist, e.g. a database set up by init-db.sh, so there is nothing to
+// compare against.
+func (db *DB) PendingMigrations(ctx context.Context, migrations fs.FS) (pending []string, ok bool, err error) {
+	all, err := listMigrations(migrations)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, false, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+	if !exists {
+		return nil, false, nil
+	}
+
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, m := range all {
+		if !applied[m.version] {
+			pending = append(pending, m.version+"_"+m.name)
+		}
+	}
+	return pending, true, nil
+}
+
+// querier is satisfied by both *DB and a pinned *sql.Conn
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, conn querier) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// listMigrations returns the up migrations ordered by version
+func listMigrations(migrations fs.FS) ([]migration, error) {
+	files, err := fs.Glob(migrations, "*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	list := make([]migration, 0, len(files))
+	seen := make(map[string]string, len(files))
+	for _, file := range files {
+		version, name, ok := strings.Cut(strings.TrimSuffix(file, ".up.sql"), "_")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("invalid migration file name %q: expected NNN_name.up.sql", file)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("duplicate migration version %s: %s and %s", version, other, file)
+		}
+		seen[version] = file
+		list = append(list, migration{version: version, name: name, file: file})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].version < list[j].version
+	})
+	return list, nil
+}
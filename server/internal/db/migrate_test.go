@@ -0,0 +1,55 @@
+package db
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/milinddethe15/ticket-booking/migrations"
+)
+
+// TestMigrationsAreRerunnable checks every up migration only uses statements
+// that succeed on a schema that already has their objects. A database set up
+// by init-db.sh before schema_migrations existed has no record of what it
+// holds, so the first RUN_MIGRATIONS run applies everything again.
+func TestMigrationsAreRerunnable(t *testing.T) {
+	checks := []struct {
+		statement *regexp.Regexp
+		guard     func(script string, match []string) bool
+		want      string
+	}{
+		{regexp.MustCompile(`(?i)CREATE\s+(?:UNIQUE\s+)?(TABLE|INDEX)\s+(\w+)`),
+			func(_ string, m []string) bool { return strings.EqualFold(m[2], "IF") }, "IF NOT EXISTS"},
+		{regexp.MustCompile(`(?i)ADD\s+COLUMN\s+(\w+)`),
+			func(_ string, m []string) bool { return strings.EqualFold(m[1], "IF") }, "IF NOT EXISTS"},
+		{regexp.MustCompile(`(?i)CREATE\s+TRIGGER\s+(\w+)`),
+			func(s string, m []string) bool {
+				return regexp.MustCompile(`(?i)DROP\s+TRIGGER\s+IF\s+EXISTS\s+` + m[1] + `\b`).MatchString(s)
+			}, "a DROP TRIGGER IF EXISTS first"},
+		{regexp.MustCompile(`(?i)ADD\s+CONSTRAINT\s+(\w+)`),
+			func(s string, m []string) bool {
+				return regexp.MustCompile(`(?i)DROP\s+CONSTRAINT\s+IF\s+EXISTS\s+` + m[1] + `\b`).MatchString(s)
+			}, "a DROP CONSTRAINT IF EXISTS first"},
+	}
+
+	comment := regexp.MustCompile(`--.*`)
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		t.Fatalf("list migrations: %v", err)
+	}
+	for _, file := range files {
+		raw, err := fs.ReadFile(migrations.FS, file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		script := comment.ReplaceAllString(string(raw), "")
+		for _, check := range checks {
+			for _, match := range check.statement.FindAllStringSubmatch(script, -1) {
+				if !check.guard(script, match) {
+					t.Errorf("%s: %q needs %s", file, match[0], check.want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package expiry releases seat locks close to the moment they expire instead
+// of waiting for the next periodic cleanup sweep.
+package expiry
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// releaseTimeout bounds a single release call made by the scheduler
+const releaseTimeout = 5 * time.Second
+
+// ReleaseFunc releases one expired lock. It must re-check the lock in the
+// database, since the scheduler's view can be stale.
+type ReleaseFunc func(ctx context.Context, ticketID int, session string) error
+
+// Scheduler keeps an in-memory min-heap of seat lock expirations for locks
+// taken by this instance. It is only a latency optimisation: entries are lost
+// on restart and locks taken by other instances are never seen, so the
+// periodic sweep remains the source of truth. A nil *Scheduler is valid and
+// does nothing.
+type Scheduler struct {
+	mu      sync.Mutex
+	queue   lockQueue
+	entries map[int]*lockEntry
+	wake    chan struct{}
+	logger  *logrus.Logger
+}
+
+func NewScheduler(logger *logrus.Logger) *Scheduler {
+	return &Scheduler{
+		entries: make(map[int]*lockEntry),
+		wake:    make(chan struct{}, 1),
+		logger:  logger,
+	}
+}
+
+// Schedule records that ticketID's lock by session expires at expiresAt,
+// replacing any earlier entry for the same ticket
+func (s *Scheduler) Schedule(ticketID int, session string, expiresAt time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if e, ok := s.entries[ticketID]; ok {
+		e.session = session
+		e.expiresAt = expiresAt
+		heap.Fix(&s.queue, e.index)
+	} else {
+		e := &lockEntry{ticketID: ticketID, session: session, expiresAt: expiresAt}
+		heap.Push(&s.queue, e)
+		s.entries[ticketID] = e
+	}
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// Cancel forgets the lock on ticketID, e.g. after an unlock or a booking
+func (s *Scheduler) Cancel(ticketIDs ...int) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	for _, ticketID := range ticketIDs {
+		if e, ok := s.entries[ticketID]; ok {
+			heap.Remove(&s.queue, e.index)
+			delete(s.entries, ticketID)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// Run releases locks as they expire until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context, release ReleaseFunc) {
+	if s == nil {
+		return
+	}
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		for _, e := range s.popDue(time.Now()) {
+			releaseCtx, cancel := context.WithTimeout(ctx, releaseTimeout)
+			if err := release(releaseCtx, e.ticketID, e.session); err != nil {
+				// The periodic sweep will pick it up
+				s.logger.WithError(err).WithField("ticket_id", e.ticketID).Warn("Failed to release expired seat lock")
+			}
+			cancel()
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(s.nextWait(time.Now()))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// popDue removes and returns every entry that has expired by now
+func (s *Scheduler) popDue(now time.Time) []*lockEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*lockEntry
+	for s.queue.Len() > 0 && !s.queue[0].expiresAt.After(now) {
+		e := heap.Pop(&s.queue).(*lockEntry)
+		delete(s.entries, e.ticketID)
+		due = append(due, e)
+	}
+	return due
+}
+
+// nextWait is the time until the earliest expiry, or an hour when idle
+func (s *Scheduler) nextWait(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queue.Len() == 0 {
+		return time.Hour
+	}
+	return s.queue[0].expiresAt.Sub(now)
+}
+
+// notify wakes Run so it can re-arm its timer for a possibly earlier expiry
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+type lockEntry struct {
+	ticketID  int
+	session   string
+	expiresAt time.Time
+	index     int
+}
+
+// lockQueue implements heap.Interface ordered by expiry
+type lockQueue []*lockEntry
+
+func (q lockQueue) Len() int           { return len(q) }
+func (q lockQueue) Less(i, j int) bool { return q[i].expiresAt.Before(q[j].expiresAt) }
+
+func (q lockQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *lockQueue) Push(x interface{}) {
+	e := x.(*lockEntry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *lockQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/repository"
+)
+
+// AdminHandler handles maintenance endpoints under /admin
+type AdminHandler struct {
+	eventRepo   *repository.EventRepository
+	bookingRepo *repository.BookingRepository
+	logger      *logrus.Logger
+}
+
+func NewAdminHandler(eventRepo *repository.EventRepository, bookingRepo *repository.BookingRepository, logger *logrus.Logger) *AdminHandler {
+	return &AdminHandler{
+		eventRepo:   eventRepo,
+		bookingRepo: bookingRepo,
+		logger:      logger,
+	}
+}
+
+// ReconcileAvailability handles POST /admin/events/:id/reconcile
+func (h *AdminHandler) ReconcileAvailability(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	if err := h.eventRepo.ReconcileAvailability(c.Request.Context(), eventID); err != nil {
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to reconcile availability")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to reconcile availability",
+		})
+		return
+	}
+
+	// Read back from the primary so the response shows this write
+	event, err := h.eventRepo.GetEventFromPrimary(c.Request.Context(), eventID)
+	if err != nil {
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get event")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve event",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    event,
+		Message: "Availability reconciled",
+	})
+}
+
+// AdjustAvailability handles POST /admin/events/:id/adjust
+func (h *AdminHandler) AdjustAvailability(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	var request models.AvailabilityAdjustment
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	if err := h.eventRepo.AdjustAvailability(c.Request.Context(), eventID, request.Delta, adminActor(c), request.Reason); err != nil {
+		statusCode := http.StatusInternalServerError
+		if contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if contains(err.Error(), "out of bounds") {
+			statusCode = http.StatusBadRequest
+		} else {
+			h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to adjust availability")
+		}
+
+		c.JSON(statusCode, &models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Read back from the primary so the response shows this write
+	event, err := h.eventRepo.GetEventFromPrimary(c.Request.Context(), eventID)
+	if err != nil {
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get event")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve event",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    event,
+		Message: "Availability adjusted",
+	})
+}
+
+// GetSeatLocks handles GET /admin/events/:id/locks
+func (h *AdminHandler) GetSeatLocks(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	locks, err := h.eventRepo.GetSeatLocks(c.Request.Context(), eventID)
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to list seat locks")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to list seat locks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    locks,
+	})
+}
+
+// ClearSeatLocks handles POST /admin/events/:id/locks/clear
+func (h *AdminHandler) ClearSeatLocks(c *gin.Context) {
+	h.clearSeatLocks(c, false)
+}
+
+// UnlockAllSeats handles POST /admin/events/:id/seats/unlock-all, the reset
+// before reopening sales: every locked seat goes back to available, whoever
+// holds it. Only a reason may be sent.
+func (h *AdminHandler) UnlockAllSeats(c *gin.Context) {
+	h.clearSeatLocks(c, true)
+}
+
+// clearSeatLocks releases the seats named in the request body, or every locked
+// seat of the event when there are none; all refuses a seat list
+func (h *AdminHandler) clearSeatLocks(c *gin.Context, all bool) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	// The body is optional; without one every locked seat is released
+	var request models.LockClearRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+	if all && len(request.SeatNumbers) > 0 {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "seat_numbers is not accepted here",
+			Message: "unlock-all releases every locked seat; use /locks/clear to release specific seats",
+		})
+		return
+	}
+
+	summary, err := h.eventRepo.ClearSeatLocks(c.Request.Context(), eventID, request.SeatNumbers, adminActor(c), request.Reason)
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to clear seat locks")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to clear seat locks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    summary,
+		Message: fmt.Sprintf("Cleared %d seat locks", summary.SeatsCleared),
+	})
+}
+
+// RebuildTickets handles POST /admin/events/:id/tickets/rebuild
+func (h *AdminHandler) RebuildTickets(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	rebuild, err := h.eventRepo.RebuildTickets(c.Request.Context(), eventID, adminActor(c))
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		var conflictErr *repository.TicketRebuildConflictError
+		if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, &models.APIResponse{
+				Success: false,
+				Error:   conflictErr.Error(),
+				Code:    "rebuild_conflict",
+				Data:    conflictErr,
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to rebuild tickets")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to rebuild tickets",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    rebuild,
+		Message: fmt.Sprintf("Added %d missing tickets", rebuild.TicketsAdded),
+	})
+}
+
+// ListBookings handles GET /admin/bookings
+func (h *AdminHandler) ListBookings(c *gin.Context) {
+	page := c.GetInt("page")
+	limit := c.GetInt("limit")
+	offset := c.GetInt("offset")
+
+	var filter models.BookingFilter
+	var err error
+
+	filter.Status = models.BookingStatus(c.Query("status"))
+	if filter.Status != "" && !filter.Status.Valid() {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid booking filter",
+			Message: fmt.Sprintf("status must be one of pending, confirmed, cancelled, expired, got %q", filter.Status),
+		})
+		return
+	}
+
+	if filter.CreatedAfter, filter.CreatedBefore, err = createdRange(c); err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid booking filter",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	bookings, total, err := h.bookingRepo.ListBookings(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list bookings")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve bookings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    bookings,
+		Meta:    &models.PageInfo{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// ForceExpireBooking handles POST /admin/bookings/:id/expire
+func (h *AdminHandler) ForceExpireBooking(c *gin.Context) {
+	bookingIDStr := c.Param("id")
+	bookingID, err := strconv.Atoi(bookingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid booking ID",
+		})
+		return
+	}
+
+	if err := h.bookingRepo.ForceExpireBooking(c.Request.Context(), bookingID, adminActor(c)); err != nil {
+		if respondInvalidTransition(c, err) {
+			return
+		}
+
+		statusCode := http.StatusInternalServerError
+		if contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else {
+			h.logger.WithError(err).WithField("booking_id", bookingID).Error("Failed to force-expire booking")
+		}
+
+		c.JSON(statusCode, &models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	booking, err := h.bookingRepo.GetBooking(c.Request.Context(), bookingID)
+	if err != nil {
+		h.logger.WithError(err).WithField("booking_id", bookingID).Error("Failed to get booking")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve booking",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    booking,
+		Message: "Booking expired and tickets released",
+	})
+}
+
+// CancelPendingBookings handles POST /api/v1/users/:id/bookings/cancel-pending
+func (h *AdminHandler) CancelPendingBookings(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+		return
+	}
+
+	summary, err := h.bookingRepo.CancelPendingBookings(c.Request.Context(), userID, adminActor(c))
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", userID).Error("Failed to cancel pending bookings")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to cancel pending bookings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    summary,
+		Message: fmt.Sprintf("Cancelled %d pending bookings", summary.Cancelled),
+	})
+}
+
+// adminActor identifies who made an admin call for the audit log. The admin
+// API key is shared, so callers name themselves with X-Admin-User; the client
+// IP is always included.
+func adminActor(c *gin.Context) string {
+	if user := c.GetHeader("X-Admin-User"); user != "" {
+		return user + "@" + c.ClientIP()
+	}
+	return "admin@" + c.ClientIP()
+}
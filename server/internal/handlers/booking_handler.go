@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"github.com/milinddethe15/ticket-booking/internal/admission"
+	"github.com/milinddethe15/ticket-booking/internal/config"
 	"github.com/milinddethe15/ticket-booking/internal/models"
 	"github.com/milinddethe15/ticket-booking/internal/repository"
 )
@@ -14,14 +20,18 @@ import (
 type BookingHandler struct {
 	bookingRepo *repository.BookingRepository
 	eventRepo   *repository.EventRepository
+	admission   *admission.Limiter
 	logger      *logrus.Logger
+	config      *config.Config
 }
 
-func NewBookingHandler(bookingRepo *repository.BookingRepository, eventRepo *repository.EventRepository, logger *logrus.Logger) *BookingHandler {
+func NewBookingHandler(bookingRepo *repository.BookingRepository, eventRepo *repository.EventRepository, limiter *admission.Limiter, logger *logrus.Logger, cfg *config.Config) *BookingHandler {
 	return &BookingHandler{
 		bookingRepo: bookingRepo,
 		eventRepo:   eventRepo,
+		admission:   limiter,
 		logger:      logger,
+		config:      cfg,
 	}
 }
 
@@ -30,16 +40,31 @@ func (h *BookingHandler) BookTickets(c *gin.Context) {
 	var request models.BookingRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		h.logger.WithError(err).Error("Invalid booking request")
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	if response := validateBuyer(&request); response != nil {
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	request.CouponCode = strings.ToUpper(strings.TrimSpace(request.CouponCode))
+
+	if request.Mode == models.BookingModeAuto && request.HoldID != "" {
 		c.JSON(http.StatusBadRequest, &models.APIResponse{
 			Success: false,
-			Error:   "Invalid request format",
-			Message: err.Error(),
+			Error:   "hold_id cannot be combined with auto mode",
 		})
 		return
 	}
 
-	// Validate event exists
+	// Validate event exists. The replica may not have an event created moments
+	// ago yet, so a miss there is confirmed on the primary.
 	event, err := h.eventRepo.GetEvent(c.Request.Context(), request.EventID)
+	if err != nil && contains(err.Error(), "not found") {
+		event, err = h.eventRepo.GetEventFromPrimary(c.Request.Context(), request.EventID)
+	}
 	if err != nil {
 		h.logger.WithError(err).WithField("event_id", request.EventID).Error("Event not found")
 		c.JSON(http.StatusNotFound, &models.APIResponse{
@@ -55,8 +80,35 @@ func (h *BookingHandler) BookTickets(c *gin.Context) {
 		"event_id":   request.EventID,
 		"event_name": event.Name,
 		"quantity":   request.Quantity,
+		"mode":       request.Mode,
 	}).Info("Booking attempt started")
 
+	// Booking locked seats only ever takes the caller's own locks. A caller
+	// without a session is given a new one, which has nothing locked, or is
+	// turned away when REQUIRE_SESSION is set. Holds and auto mode don't use it.
+	if request.HoldID == "" && request.Mode != models.BookingModeAuto {
+		session, ok := requestSession(c, h.config.App.RequireSession)
+		if !ok {
+			return
+		}
+		request.SessionID = session
+	}
+
+	// Shed load before opening a transaction that would only queue on the
+	// event row behind every other booking for it
+	release, ok := h.admission.TryAcquire(request.EventID)
+	if !ok {
+		h.logger.WithField("event_id", request.EventID).Warn("Booking rejected by admission control")
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusServiceUnavailable, &models.APIResponse{
+			Success: false,
+			Error:   "This event is in high demand right now. Please retry in a moment.",
+			Code:    "high_demand",
+		})
+		return
+	}
+	defer release()
+
 	// Attempt to book tickets
 	booking, err := h.bookingRepo.BookTickets(c.Request.Context(), &request)
 	if err != nil {
@@ -66,13 +118,59 @@ func (h *BookingHandler) BookTickets(c *gin.Context) {
 			"quantity": request.Quantity,
 		}).Error("Booking failed")
 
+		if respondSalesPaused(c, err) {
+			return
+		}
+
+		var lockErr *repository.InsufficientLockedSeatsError
+		if errors.As(err, &lockErr) {
+			c.JSON(http.StatusConflict, &models.APIResponse{
+				Success: false,
+				Error:   lockErr.Error(),
+				Code:    "insufficient_locked_seats",
+				Data:    lockErr,
+			})
+			return
+		}
+
+		// The repository has already logged the corrupt counter for ops; the
+		// client only needs to know the seats it was shown aren't there
+		var inventoryErr *repository.InventoryInconsistencyError
+		if errors.As(err, &inventoryErr) {
+			c.JSON(http.StatusConflict, &models.APIResponse{
+				Success: false,
+				Error:   "No tickets are currently available for this event, although it is listed with seats remaining. Please try again later.",
+				Code:    "inventory_inconsistent",
+			})
+			return
+		}
+
+		var couponErr *repository.InvalidCouponError
+		if errors.As(err, &couponErr) {
+			c.JSON(http.StatusBadRequest, &models.APIResponse{
+				Success: false,
+				Error:   couponErr.Error(),
+				Code:    "invalid_coupon",
+				Data:    couponErr,
+			})
+			return
+		}
+
 		// Determine appropriate HTTP status code based on error
 		statusCode := http.StatusInternalServerError
 		if contains(err.Error(), "insufficient tickets") ||
 			contains(err.Error(), "not found") {
 			statusCode = http.StatusBadRequest
-		} else if contains(err.Error(), "already started") {
+		} else if contains(err.Error(), "already started") ||
+			contains(err.Error(), "sales closed") ||
+			contains(err.Error(), "already ended") ||
+			contains(err.Error(), "exceeds the maximum") ||
+			contains(err.Error(), "per-user limit") {
 			statusCode = http.StatusBadRequest
+		} else if contains(err.Error(), "hold does not") ||
+			contains(err.Error(), "hold is no longer active") ||
+			contains(err.Error(), "hold has") {
+			statusCode = http.StatusConflict
 		}
 
 		c.JSON(statusCode, &models.APIResponse{
@@ -91,14 +189,19 @@ func (h *BookingHandler) BookTickets(c *gin.Context) {
 		"total_amount": booking.TotalAmount,
 	}).Info("Booking successful")
 
+	message := "Tickets booked successfully. Please complete payment within 15 minutes."
+	if !booking.PaymentRequired {
+		message = "Tickets booked successfully. No payment required."
+	}
+
 	c.JSON(http.StatusCreated, &models.APIResponse{
 		Success: true,
 		Data:    booking,
-		Message: "Tickets booked successfully. Please complete payment within 15 minutes.",
+		Message: message,
 	})
 }
 
-// GetBooking handles GET /api/bookings/:id
+// GetBooking handles GET /api/bookings/:id[?expand=event]
 func (h *BookingHandler) GetBooking(c *gin.Context) {
 	bookingIDStr := c.Param("id")
 	bookingID, err := strconv.Atoi(bookingIDStr)
@@ -110,7 +213,20 @@ func (h *BookingHandler) GetBooking(c *gin.Context) {
 		return
 	}
 
-	booking, err := h.bookingRepo.GetBooking(c.Request.Context(), bookingID)
+	var booking *models.Booking
+	switch expand := c.Query("expand"); expand {
+	case "":
+		booking, err = h.bookingRepo.GetBooking(c.Request.Context(), bookingID)
+	case "event":
+		booking, err = h.bookingRepo.GetBookingWithEvent(c.Request.Context(), bookingID)
+	default:
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid expand parameter",
+			Message: fmt.Sprintf("expand must be \"event\", got %q", expand),
+		})
+		return
+	}
 	if err != nil {
 		if contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, &models.APIResponse{
@@ -134,6 +250,51 @@ func (h *BookingHandler) GetBooking(c *gin.Context) {
 	})
 }
 
+// GetBookingStatuses handles POST /api/bookings/status
+func (h *BookingHandler) GetBookingStatuses(c *gin.Context) {
+	var request models.BookingStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	if len(request.IDs) == 0 && len(request.Refs) == 0 {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid request format",
+			Code:    "validation_failed",
+			Data:    map[string]string{"ids": "ids or refs must list at least one booking"},
+		})
+		return
+	}
+	if len(request.IDs)+len(request.Refs) > models.MaxBookingStatusBatch {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid request format",
+			Code:    "validation_failed",
+			Data:    map[string]string{"ids": fmt.Sprintf("ids and refs together must have at most %d items", models.MaxBookingStatusBatch)},
+		})
+		return
+	}
+
+	// There is no caller identity yet, so this returns any booking asked for,
+	// as GetBooking does; filter by owner here once requests are authenticated
+	entries, err := h.bookingRepo.GetBookingStatuses(c.Request.Context(), request.IDs, request.Refs)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to look up booking statuses")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve bookings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
 // ConfirmBooking handles POST /api/bookings/:id/confirm
 func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 	bookingIDStr := c.Param("id")
@@ -146,15 +307,39 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 		return
 	}
 
-	err = h.bookingRepo.ConfirmBooking(c.Request.Context(), bookingID)
+	// The body is optional: free events and manual settlement confirm without one
+	var request models.BookingConfirmation
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+	request.PaymentRef = strings.TrimSpace(request.PaymentRef)
+
+	err = h.bookingRepo.ConfirmBooking(c.Request.Context(), bookingID, request.PaymentRef)
 	if err != nil {
 		h.logger.WithError(err).WithField("booking_id", bookingID).Error("Failed to confirm booking")
 
+		if respondInvalidTransition(c, err) || respondPaymentError(c, err) {
+			return
+		}
+
+		var mismatchErr *repository.TicketConfirmationError
+		if errors.As(err, &mismatchErr) {
+			c.JSON(http.StatusConflict, &models.APIResponse{
+				Success: false,
+				Error:   "booking can no longer be confirmed; seats were released",
+				Code:    "booking_lapsed",
+				Data:    mismatchErr,
+			})
+			return
+		}
+
 		statusCode := http.StatusInternalServerError
 		if contains(err.Error(), "not found") ||
-			contains(err.Error(), "not in pending status") ||
 			contains(err.Error(), "expired") {
 			statusCode = http.StatusBadRequest
+		} else if contains(err.Error(), "already been used") {
+			statusCode = http.StatusConflict
 		}
 
 		c.JSON(statusCode, &models.APIResponse{
@@ -171,6 +356,62 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 	})
 }
 
+// ModifyBooking handles POST /api/bookings/:id/modify
+func (h *BookingHandler) ModifyBooking(c *gin.Context) {
+	bookingIDStr := c.Param("id")
+	bookingID, err := strconv.Atoi(bookingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid booking ID",
+		})
+		return
+	}
+
+	var request models.BookingModification
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	booking, err := h.bookingRepo.ModifyBooking(c.Request.Context(), bookingID, request.Quantity)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"booking_id": bookingID,
+			"quantity":   request.Quantity,
+		}).Error("Failed to modify booking")
+
+		if respondInvalidTransition(c, err) || respondSalesPaused(c, err) {
+			return
+		}
+
+		statusCode := http.StatusInternalServerError
+		if contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if contains(err.Error(), "expired") {
+			statusCode = http.StatusConflict
+		} else if contains(err.Error(), "insufficient tickets") ||
+			contains(err.Error(), "already started") ||
+			contains(err.Error(), "sales closed") ||
+			contains(err.Error(), "exceeds the maximum") ||
+			contains(err.Error(), "per-user limit") {
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, &models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    booking,
+		Message: "Booking updated successfully",
+	})
+}
+
 // CancelBooking handles POST /api/bookings/:id/cancel
 func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	bookingIDStr := c.Param("id")
@@ -187,9 +428,12 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	if err != nil {
 		h.logger.WithError(err).WithField("booking_id", bookingID).Error("Failed to cancel booking")
 
+		if respondInvalidTransition(c, err) {
+			return
+		}
+
 		statusCode := http.StatusInternalServerError
-		if contains(err.Error(), "not found") ||
-			contains(err.Error(), "already cancelled") {
+		if contains(err.Error(), "not found") {
 			statusCode = http.StatusBadRequest
 		}
 
@@ -207,6 +451,81 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	})
 }
 
+// validateBuyer requires exactly one of user_id and guest contact details,
+// reported per field like binding errors. Guest details are trimmed and the
+// email lower-cased so per-buyer limits match however it was typed.
+func validateBuyer(request *models.BookingRequest) *models.APIResponse {
+	fields := map[string]string{}
+
+	switch {
+	case request.UserID == 0 && request.Guest == nil:
+		fields["user_id"] = "is required unless guest contact details are given"
+	case request.UserID != 0 && request.Guest != nil:
+		fields["guest"] = "cannot be combined with user_id"
+	case request.Guest != nil:
+		request.Guest.Name = strings.TrimSpace(request.Guest.Name)
+		request.Guest.Email = strings.ToLower(strings.TrimSpace(request.Guest.Email))
+		request.Guest.Phone = strings.TrimSpace(request.Guest.Phone)
+		if request.Guest.Name == "" {
+			fields["guest.name"] = "is required"
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &models.APIResponse{
+		Success: false,
+		Error:   "Invalid request format",
+		Code:    "validation_failed",
+		Data:    fields,
+	}
+}
+
+// respondPaymentError answers 402 when err rejects a booking's payment
+// reference, and 503 when there is no payment verifier to check it with
+func respondPaymentError(c *gin.Context, err error) bool {
+	var verifyErr *repository.PaymentVerificationError
+	if errors.As(err, &verifyErr) {
+		c.JSON(http.StatusPaymentRequired, &models.APIResponse{
+			Success: false,
+			Error:   "Payment could not be verified; the booking is still pending",
+			Code:    "payment_not_verified",
+			Data:    verifyErr,
+		})
+		return true
+	}
+
+	var unavailableErr *repository.PaymentVerifierUnavailableError
+	if errors.As(err, &unavailableErr) {
+		c.JSON(http.StatusServiceUnavailable, &models.APIResponse{
+			Success: false,
+			Error:   "Payments cannot be verified right now; the booking is still pending",
+			Code:    "payment_verification_unavailable",
+			Data:    unavailableErr,
+		})
+		return true
+	}
+
+	return false
+}
+
+// respondInvalidTransition answers 409 when err rejects a booking status change
+func respondInvalidTransition(c *gin.Context, err error) bool {
+	var transitionErr *repository.InvalidTransitionError
+	if !errors.As(err, &transitionErr) {
+		return false
+	}
+
+	c.JSON(http.StatusConflict, &models.APIResponse{
+		Success: false,
+		Error:   transitionErr.Error(),
+		Code:    "invalid_transition",
+		Data:    transitionErr,
+	})
+	return true
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) &&
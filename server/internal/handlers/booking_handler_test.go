@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/repository"
+)
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestValidateBuyer(t *testing.T) {
+	tests := []struct {
+		name    string
+		request models.BookingRequest
+		fields  map[string]string // nil when the buyer is valid
+	}{
+		{
+			name:    "user only",
+			request: models.BookingRequest{UserID: 7},
+		},
+		{
+			name:    "guest only",
+			request: models.BookingRequest{Guest: &models.GuestContact{Name: "Ada", Email: "ada@example.com"}},
+		},
+		{
+			name: "both",
+			request: models.BookingRequest{
+				UserID: 7,
+				Guest:  &models.GuestContact{Name: "Ada", Email: "ada@example.com"},
+			},
+			fields: map[string]string{"guest": "cannot be combined with user_id"},
+		},
+		{
+			name:    "neither",
+			request: models.BookingRequest{},
+			fields:  map[string]string{"user_id": "is required unless guest contact details are given"},
+		},
+		{
+			name:    "guest with blank name",
+			request: models.BookingRequest{Guest: &models.GuestContact{Name: "   ", Email: "ada@example.com"}},
+			fields:  map[string]string{"guest.name": "is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+			response := validateBuyer(&request)
+
+			if tt.fields == nil {
+				if response != nil {
+					t.Errorf("valid buyer rejected: %+v", response)
+				}
+				return
+			}
+			if response == nil {
+				t.Fatal("invalid buyer accepted")
+			}
+			if response.Code != "validation_failed" {
+				t.Errorf("code = %q, want validation_failed", response.Code)
+			}
+			fields, _ := response.Data.(map[string]string)
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("fields = %v, want %v", fields, tt.fields)
+			}
+			for field, message := range tt.fields {
+				if fields[field] != message {
+					t.Errorf("fields[%s] = %q, want %q", field, fields[field], message)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateBuyerNormalisesGuest(t *testing.T) {
+	request := models.BookingRequest{Guest: &models.GuestContact{
+		Name:  "  Ada Lovelace ",
+		Email: " Ada@Example.COM ",
+		Phone: " +44 20 7946 0000 ",
+	}}
+
+	if response := validateBuyer(&request); response != nil {
+		t.Fatalf("valid guest rejected: %+v", response)
+	}
+	if got := *request.Guest; got.Name != "Ada Lovelace" || got.Email != "ada@example.com" || got.Phone != "+44 20 7946 0000" {
+		t.Errorf("guest = %+v, want trimmed with a lower-cased email", got)
+	}
+}
+
+// TestBookTicketsRejectsBuyer checks the buyer is validated before the
+// handler touches the repositories, which are nil here
+func TestBookTicketsRejectsBuyer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewBookingHandler(nil, nil, nil, testLogger(), &config.Config{})
+
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"neither", `{"event_id":1,"quantity":1}`, "user_id"},
+		{"both", `{"user_id":7,"guest":{"name":"Ada","email":"ada@example.com"},"event_id":1,"quantity":1}`, "guest"},
+		{"guest without email", `{"guest":{"name":"Ada"},"event_id":1,"quantity":1}`, "guest.email"},
+		{"guest with bad email", `{"guest":{"name":"Ada","email":"not-an-email"},"event_id":1,"quantity":1}`, "guest.email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/bookings", handler.BookTickets)
+
+			req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body.String())
+			}
+			var response struct {
+				Code string            `json:"code"`
+				Data map[string]string `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if _, ok := response.Data[tt.field]; !ok {
+				t.Errorf("field errors = %v, want one for %s", response.Data, tt.field)
+			}
+		})
+	}
+}
+
+func TestRespondPaymentError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		err     error
+		handled bool
+		status  int
+		code    string
+	}{
+		{"verification failed", &repository.PaymentVerificationError{BookingID: 1, PaymentRef: "pay_x", Reason: "declined"},
+			true, http.StatusPaymentRequired, "payment_not_verified"},
+		{"no verifier", fmt.Errorf("confirm: %w", &repository.PaymentVerifierUnavailableError{BookingID: 1}),
+			true, http.StatusServiceUnavailable, "payment_verification_unavailable"},
+		{"other error", errors.New("booking has expired"), false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			if handled := respondPaymentError(c, tt.err); handled != tt.handled {
+				t.Fatalf("handled = %v, want %v", handled, tt.handled)
+			}
+			if !tt.handled {
+				return
+			}
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if response.Code != tt.code {
+				t.Errorf("code = %q, want %q", response.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/repository"
+)
+
+type CheckInHandler struct {
+	eventRepo *repository.EventRepository
+	logger    *logrus.Logger
+}
+
+func NewCheckInHandler(eventRepo *repository.EventRepository, logger *logrus.Logger) *CheckInHandler {
+	return &CheckInHandler{
+		eventRepo: eventRepo,
+		logger:    logger,
+	}
+}
+
+// CheckInTicket handles POST /api/v1/tickets/:id/checkin
+func (h *CheckInHandler) CheckInTicket(c *gin.Context) {
+	ticketID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid ticket ID",
+		})
+		return
+	}
+
+	checkIn, err := h.eventRepo.CheckInTicket(c.Request.Context(), ticketID, gateActor(c))
+	h.respond(c, checkIn, err)
+}
+
+// CheckInBySeat handles POST /api/v1/checkin with a booking reference and seat
+func (h *CheckInHandler) CheckInBySeat(c *gin.Context) {
+	var request models.CheckInRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	checkIn, err := h.eventRepo.CheckInBySeat(c.Request.Context(), request.BookingRef, request.SeatNo, gateActor(c))
+	h.respond(c, checkIn, err)
+}
+
+func (h *CheckInHandler) respond(c *gin.Context, checkIn *models.CheckIn, err error) {
+	if err == nil {
+		c.JSON(http.StatusOK, &models.APIResponse{
+			Success: true,
+			Data:    checkIn,
+			Message: "Ticket checked in",
+		})
+		return
+	}
+
+	var checkedInErr *repository.AlreadyCheckedInError
+	if errors.As(err, &checkedInErr) {
+		c.JSON(http.StatusConflict, &models.APIResponse{
+			Success: false,
+			Error:   checkedInErr.Error(),
+			Code:    "already_checked_in",
+			Data:    checkedInErr,
+		})
+		return
+	}
+
+	switch {
+	case contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, &models.APIResponse{
+			Success: false,
+			Error:   "Ticket not found",
+		})
+	case contains(err.Error(), "is not sold"):
+		c.JSON(http.StatusConflict, &models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ticket_not_sold",
+		})
+	default:
+		h.logger.WithError(err).Error("Failed to check in ticket")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to check in ticket",
+		})
+	}
+}
+
+// gateActor identifies who scanned a ticket. Gate staff share the gate key,
+// so scanners name themselves (or their lane) with X-Gate-User; the client IP
+// is always included.
+func gateActor(c *gin.Context) string {
+	if user := c.GetHeader("X-Gate-User"); user != "" {
+		return user + "@" + c.ClientIP()
+	}
+	return "gate@" + c.ClientIP()
+}
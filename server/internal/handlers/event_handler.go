@@ -1,26 +1,38 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/middleware"
 	"github.com/milinddethe15/ticket-booking/internal/models"
 	"github.com/milinddethe15/ticket-booking/internal/repository"
+	"github.com/milinddethe15/ticket-booking/internal/seating"
 )
 
 type EventHandler struct {
 	eventRepo *repository.EventRepository
 	logger    *logrus.Logger
+	config    *config.Config
 }
 
-func NewEventHandler(eventRepo *repository.EventRepository, logger *logrus.Logger) *EventHandler {
+func NewEventHandler(eventRepo *repository.EventRepository, logger *logrus.Logger, cfg *config.Config) *EventHandler {
 	return &EventHandler{
 		eventRepo: eventRepo,
 		logger:    logger,
+		config:    cfg,
 	}
 }
 
@@ -30,17 +42,27 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 	limit := c.GetInt("limit")
 	offset := c.GetInt("offset")
 
-	events, err := h.eventRepo.GetEvents(c.Request.Context(), limit, offset)
+	filter, err := eventFilter(c)
+	if err != nil {
+		respond(c, http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event filter",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	events, err := h.eventRepo.GetEvents(c.Request.Context(), filter, limit, offset)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get events")
-		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+		respond(c, http.StatusInternalServerError, &models.APIResponse{
 			Success: false,
 			Error:   "Failed to retrieve events",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, &models.APIResponse{
+	respond(c, http.StatusOK, &models.APIResponse{
 		Success: true,
 		Data:    events,
 	})
@@ -48,6 +70,579 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 
 // GetEvent handles GET /api/events/:id
 func (h *EventHandler) GetEvent(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		respond(c, http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := h.eventRepo.GetEvent(c.Request.Context(), eventID)
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			respond(c, http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get event")
+		respond(c, http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve event",
+		})
+		return
+	}
+
+	respond(c, http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    event,
+	})
+}
+
+// CreateEvent handles POST /api/events
+func (h *EventHandler) CreateEvent(c *gin.Context) {
+	var event models.Event
+	if err := c.ShouldBindJSON(&event); err != nil {
+		h.logger.WithError(err).Error("Invalid event request")
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &event))
+		return
+	}
+
+	// Re-running a provisioning script returns the event it created the
+	// first time, even if that event has since started
+	event.ExternalRef = strings.TrimSpace(event.ExternalRef)
+	if len(event.ExternalRef) > 255 {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "External ref cannot exceed 255 characters",
+		})
+		return
+	}
+	if event.ExternalRef != "" && h.respondWithExistingEvent(c, event.ExternalRef) {
+		return
+	}
+
+	allowPast := h.allowPastEvents(c)
+	if resp := h.validateEvent(&event, allowPast); resp != nil {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	createdEvent, err := h.eventRepo.CreateEvent(c.Request.Context(), &event)
+	if err != nil {
+		// A concurrent request with the same external_ref won the insert
+		if contains(err.Error(), "already exists") && h.respondWithExistingEvent(c, event.ExternalRef) {
+			return
+		}
+
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"event_name":    event.Name,
+			"total_tickets": event.TotalTickets,
+		}).Error("Failed to create event")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to create event",
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"event_id":      createdEvent.ID,
+		"event_name":    createdEvent.Name,
+		"total_tickets": createdEvent.TotalTickets,
+	}).Info("Event created successfully")
+	if allowPast {
+		h.auditPastEvent(c, createdEvent)
+	}
+
+	c.JSON(http.StatusCreated, &models.APIResponse{
+		Success: true,
+		Data:    createdEvent,
+		Message: "Event created successfully",
+	})
+}
+
+// CreateSeries handles POST /api/events/series
+func (h *EventHandler) CreateSeries(c *gin.Context) {
+	var request models.SeriesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.WithError(err).Error("Invalid series request")
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	base := &request.Event
+	rec := request.Recurrence
+
+	// external_ref is unique per event, so it cannot be shared by occurrences
+	if strings.TrimSpace(base.ExternalRef) != "" {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "External ref is not supported for event series",
+		})
+		return
+	}
+
+	if (rec.Count == 0) == (rec.Until == nil) {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Recurrence needs exactly one of count or until",
+		})
+		return
+	}
+
+	allowPast := h.allowPastEvents(c)
+	if resp := h.validateEvent(base, allowPast); resp != nil {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	startTimes := rec.StartTimes(base.StartTime)
+	if len(startTimes) == 0 {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Recurrence produces no occurrences",
+		})
+		return
+	}
+	if len(startTimes) > models.MaxSeriesOccurrences {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("A series cannot have more than %d occurrences", models.MaxSeriesOccurrences),
+		})
+		return
+	}
+
+	now := time.Now()
+	for _, start := range startTimes {
+		if !allowPast && !start.After(now) {
+			c.JSON(http.StatusBadRequest, &models.APIResponse{
+				Success: false,
+				Error:   "Event start time cannot be in the past",
+				Message: fmt.Sprintf("occurrence at %s", start.Format(time.RFC3339)),
+			})
+			return
+		}
+	}
+
+	series, err := h.eventRepo.CreateSeries(c.Request.Context(), base, rec.Frequency, startTimes)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"event_name":  base.Name,
+			"frequency":   rec.Frequency,
+			"occurrences": len(startTimes),
+		}).Error("Failed to create event series")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to create event series",
+		})
+		return
+	}
+
+	if allowPast {
+		for _, occurrence := range series.Events {
+			h.auditPastEvent(c, occurrence)
+		}
+	}
+
+	c.JSON(http.StatusCreated, &models.APIResponse{
+		Success: true,
+		Data:    series,
+		Message: fmt.Sprintf("Event series created with %d occurrences", len(series.Events)),
+	})
+}
+
+// GetSeries handles GET /api/series/:id
+func (h *EventHandler) GetSeries(c *gin.Context) {
+	seriesIDStr := c.Param("id")
+	seriesID, err := strconv.Atoi(seriesIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid series ID",
+		})
+		return
+	}
+
+	series, err := h.eventRepo.GetSeries(c.Request.Context(), seriesID)
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Series not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("series_id", seriesID).Error("Failed to get series")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve series",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    series,
+	})
+}
+
+// allowPastEvents reports whether this request may create events that have
+// already started: ALLOW_PAST_EVENTS must be on and the caller must send the
+// admin key
+func (h *EventHandler) allowPastEvents(c *gin.Context) bool {
+	return h.config.App.AllowPastEvents && middleware.HasAdminKey(c, h.config.App.AdminAPIKey)
+}
+
+// auditPastEvent records an event created with a start time in the past, which
+// only imports are meant to do
+func (h *EventHandler) auditPastEvent(c *gin.Context, event *models.Event) {
+	if event.StartTime.After(time.Now()) {
+		return
+	}
+	h.logger.WithFields(logrus.Fields{
+		"audit":      true,
+		"action":     "create_past_event",
+		"actor":      adminActor(c),
+		"event_id":   event.ID,
+		"start_time": event.StartTime,
+	}).Info("Event created with a past start time")
+}
+
+// validateEvent checks and normalises an event before it is created. It
+// returns the response to send when the event is rejected. allowPast skips the
+// start-in-the-future check but not the others.
+func (h *EventHandler) validateEvent(event *models.Event, allowPast bool) *models.APIResponse {
+	if fields := normalizeEventText(event); len(fields) > 0 {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Invalid request format",
+			Code:    "validation_failed",
+			Data:    fields,
+		}
+	}
+
+	// Validate event dates
+	if !allowPast && event.StartTime.Before(time.Now()) {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Event start time cannot be in the past",
+		}
+	}
+
+	if event.EndTime.Before(event.StartTime) {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Event end time must be after start time",
+		}
+	}
+
+	// Validate ticket count
+	if event.TotalTickets <= 0 || event.TotalTickets > 10000 {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Total tickets must be between 1 and 10,000",
+		}
+	}
+
+	// Validate per-booking cap; the global limit still applies on top of it
+	if event.MaxPerBooking < 0 || event.MaxPerBooking > models.MaxTicketsPerBooking {
+		return &models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Max per booking must be between 1 and %d", models.MaxTicketsPerBooking),
+		}
+	}
+
+	if event.SalesCloseOffset < 0 {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Sales close offset cannot be negative",
+		}
+	}
+
+	if event.SeatLockDuration < 0 {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Seat lock duration must be a positive number of seconds",
+		}
+	}
+
+	if event.MaxPerUser < 0 {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Max per user cannot be negative",
+		}
+	}
+
+	// Validate seat labels: an explicit list takes precedence over a labelling format
+	if len(event.SeatLabels) > 0 {
+		event.SeatLabelFormat = ""
+		event.SeatRows = 0
+		if err := seating.ValidateLabels(event.SeatLabels, event.TotalTickets); err != nil {
+			return &models.APIResponse{
+				Success: false,
+				Error:   "Invalid seat labels",
+				Message: err.Error(),
+			}
+		}
+	} else if _, err := seating.GenerateLabels(event.SeatLabelFormat, event.SeatRows, event.TotalTickets); err != nil {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Invalid seat label format",
+			Message: err.Error(),
+		}
+	}
+
+	// Validate price
+	if event.Price < 0 {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Price cannot be negative",
+		}
+	}
+
+	// Validate currency (ISO 4217 alphabetic code)
+	event.Currency = strings.ToUpper(event.Currency)
+	if event.Currency == "" {
+		event.Currency = h.config.App.DefaultCurrency
+	}
+	if !models.IsCurrencyCode(event.Currency) {
+		return &models.APIResponse{
+			Success: false,
+			Error:   "Currency must be an ISO 4217 currency code",
+			Message: fmt.Sprintf("unknown currency %q", event.Currency),
+		}
+	}
+
+	return nil
+}
+
+// normalizeEventText trims the event's name, venue and description and
+// returns a message per field that is missing, too long or contains control
+// characters, plus a message for image or banner URLs that aren't http(s).
+// Descriptions may span lines; names and venues may not.
+func normalizeEventText(event *models.Event) map[string]string {
+	fields := make(map[string]string)
+
+	check := func(field string, value *string, maxLength int, required, multiline bool) {
+		*value = strings.TrimSpace(*value)
+		switch {
+		case required && *value == "":
+			fields[field] = "is required"
+		case utf8.RuneCountInString(*value) > maxLength:
+			fields[field] = fmt.Sprintf("must be at most %d characters", maxLength)
+		case strings.IndexFunc(*value, func(r rune) bool {
+			return unicode.IsControl(r) && !(multiline && (r == '\n' || r == '\r' || r == '\t'))
+		}) >= 0:
+			fields[field] = "must not contain control characters"
+		}
+	}
+
+	check("name", &event.Name, models.MaxEventNameLength, true, false)
+	check("venue", &event.Venue, models.MaxEventVenueLength, true, false)
+	check("description", &event.Description, models.MaxEventDescriptionLength, false, true)
+
+	checkURL := func(field string, value *string) {
+		*value = strings.TrimSpace(*value)
+		if *value == "" {
+			return
+		}
+		if len(*value) > models.MaxEventURLLength {
+			fields[field] = fmt.Sprintf("must be at most %d characters", models.MaxEventURLLength)
+			return
+		}
+		parsed, err := url.Parse(*value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			fields[field] = "must be an http or https URL"
+		}
+	}
+
+	checkURL("image_url", &event.ImageURL)
+	checkURL("banner_url", &event.BannerURL)
+
+	return fields
+}
+
+// respondWithExistingEvent answers a create request whose external_ref is
+// already taken. It reports false if there is no such event.
+func (h *EventHandler) respondWithExistingEvent(c *gin.Context, externalRef string) bool {
+	existing, err := h.eventRepo.GetEventByExternalRef(c.Request.Context(), externalRef)
+	if err != nil {
+		if !contains(err.Error(), "not found") {
+			h.logger.WithError(err).WithField("external_ref", externalRef).Error("Failed to look up event by external ref")
+		}
+		return false
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    existing,
+		Message: "Event already exists for this external_ref",
+	})
+	return true
+}
+
+// GetTickets handles GET /api/events/:id/tickets. The optional ?status=
+// filter defaults to available seats.
+func (h *EventHandler) GetTickets(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		respond(c, http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	status := models.TicketStatus(c.DefaultQuery("status", string(models.TicketAvailable)))
+	if !status.Valid() {
+		respond(c, http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid ticket status",
+			Message: fmt.Sprintf("status must be one of %s, %s, %s, %s", models.TicketAvailable, models.TicketLocked, models.TicketReserved, models.TicketSold),
+		})
+		return
+	}
+
+	// Get pagination parameters from middleware
+	page := c.GetInt("page")
+	limit := c.GetInt("limit")
+	offset := c.GetInt("offset")
+
+	tickets, err := h.eventRepo.GetTickets(c.Request.Context(), eventID, status, limit, offset)
+	var total int
+	if err == nil {
+		total, err = h.eventRepo.CountTickets(c.Request.Context(), eventID, status)
+	}
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"event_id": eventID,
+			"status":   status,
+		}).Error("Failed to get tickets")
+		respond(c, http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve tickets",
+		})
+		return
+	}
+
+	respond(c, http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    tickets,
+		Meta:    &models.PageInfo{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// GetAllTickets handles GET /api/events/:id/tickets/all
+func (h *EventHandler) GetAllTickets(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		respond(c, http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	limit, err := middleware.ParseLimit(c, h.config.App.DefaultSeatListSize, h.config.App.MaxSeatListSize,
+		middleware.StrictPagination(c, h.config.App.StrictPagination))
+	if err != nil {
+		respond(c, http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid pagination parameters",
+			Code:    "invalid_pagination",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Continue after the last seat of the previous page
+	after := c.Query("after")
+
+	tickets, more, err := h.eventRepo.GetAllTickets(c.Request.Context(), eventID, after, limit)
+	var total int
+	if err == nil {
+		total, err = h.eventRepo.CountTickets(c.Request.Context(), eventID, "")
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get all tickets")
+		respond(c, http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve all tickets",
+		})
+		return
+	}
+
+	meta := &models.PageInfo{Limit: limit, Total: total}
+	if more {
+		meta.Truncated = true
+		meta.NextCursor = tickets[len(tickets)-1].SeatNo
+	}
+
+	respond(c, http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    tickets,
+		Meta:    meta,
+	})
+}
+
+// CheckAvailability handles GET /api/events/:id/availability
+func (h *EventHandler) CheckAvailability(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	quantity, err := strconv.Atoi(c.DefaultQuery("quantity", "1"))
+	if err != nil || quantity < 1 || quantity > models.MaxTicketsPerBooking {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Quantity must be between 1 and %d", models.MaxTicketsPerBooking),
+		})
+		return
+	}
+
+	check, err := h.eventRepo.CheckAvailability(c.Request.Context(), eventID, quantity)
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to check availability")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to check availability",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    check,
+	})
+}
+
+// GetSessionHolds handles GET /api/events/:id/holds, the seats a session holds
+// for an event. The session comes from ?session= or the usual X-Session-ID
+// header or cookie; without one the summary is empty.
+func (h *EventHandler) GetSessionHolds(c *gin.Context) {
 	eventIDStr := c.Param("id")
 	eventID, err := strconv.Atoi(eventIDStr)
 	if err != nil {
@@ -58,7 +653,12 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := h.eventRepo.GetEvent(c.Request.Context(), eventID)
+	session := c.Query("session")
+	if session == "" {
+		session = existingSession(c)
+	}
+
+	holds, err := h.eventRepo.GetSessionHolds(c.Request.Context(), eventID, session)
 	if err != nil {
 		if contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, &models.APIResponse{
@@ -68,96 +668,258 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 			return
 		}
 
-		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get event")
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get session holds")
 		c.JSON(http.StatusInternalServerError, &models.APIResponse{
 			Success: false,
-			Error:   "Failed to retrieve event",
+			Error:   "Failed to get held seats",
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, &models.APIResponse{
 		Success: true,
-		Data:    event,
+		Data:    holds,
 	})
 }
 
-// CreateEvent handles POST /api/events
-func (h *EventHandler) CreateEvent(c *gin.Context) {
-	var event models.Event
-	if err := c.ShouldBindJSON(&event); err != nil {
-		h.logger.WithError(err).Error("Invalid event request")
+// ListSessionHolds handles GET /api/holds, a session's held seats across all
+// events grouped by event. The session is resolved as in GetSessionHolds, and
+// pages count events rather than seats.
+func (h *EventHandler) ListSessionHolds(c *gin.Context) {
+	page := c.GetInt("page")
+	limit := c.GetInt("limit")
+	offset := c.GetInt("offset")
+
+	session := c.Query("session")
+	if session == "" {
+		session = existingSession(c)
+	}
+	if session == "" {
+		c.JSON(http.StatusOK, &models.APIResponse{
+			Success: true,
+			Data:    []*models.SessionHolds{},
+			Meta:    &models.PageInfo{Page: page, Limit: limit},
+		})
+		return
+	}
+
+	holds, total, err := h.eventRepo.ListSessionHolds(c.Request.Context(), session, limit, offset)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list session holds")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to get held seats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    holds,
+		Meta:    &models.PageInfo{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// GetUserEvents handles GET /api/v1/users/:id/events, the events a user has
+// pending or confirmed bookings for. ?timeframe=upcoming or past narrows it to
+// events that have not ended yet or have.
+func (h *EventHandler) GetUserEvents(c *gin.Context) {
+	page := c.GetInt("page")
+	limit := c.GetInt("limit")
+	offset := c.GetInt("offset")
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID < 1 {
 		c.JSON(http.StatusBadRequest, &models.APIResponse{
 			Success: false,
-			Error:   "Invalid request format",
-			Message: err.Error(),
+			Error:   "Invalid user ID",
 		})
 		return
 	}
 
-	// Validate event dates
-	if event.StartTime.Before(time.Now()) {
+	timeframe := models.EventTimeframe(c.Query("timeframe"))
+	if timeframe != "" && !timeframe.Valid() {
 		c.JSON(http.StatusBadRequest, &models.APIResponse{
 			Success: false,
-			Error:   "Event start time cannot be in the past",
+			Error:   "Invalid event filter",
+			Message: fmt.Sprintf("timeframe must be upcoming or past, got %q", timeframe),
 		})
 		return
 	}
 
-	if event.EndTime.Before(event.StartTime) {
+	userEvents, total, err := h.eventRepo.GetUserEvents(c.Request.Context(), userID, timeframe, limit, offset)
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", userID).Error("Failed to get user events")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    userEvents,
+		Meta:    &models.PageInfo{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// QuoteBooking handles POST /api/events/:id/quote, the price breakdown of a
+// booking for the given seats or quantity. Nothing is locked or stored.
+func (h *EventHandler) QuoteBooking(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, &models.APIResponse{
 			Success: false,
-			Error:   "Event end time must be after start time",
+			Error:   "Invalid event ID",
 		})
 		return
 	}
 
-	// Validate ticket count
-	if event.TotalTickets <= 0 || event.TotalTickets > 10000 {
+	var request models.QuoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	if (len(request.SeatNumbers) > 0) == (request.Quantity > 0) {
 		c.JSON(http.StatusBadRequest, &models.APIResponse{
 			Success: false,
-			Error:   "Total tickets must be between 1 and 10,000",
+			Error:   "Quote needs exactly one of seat_numbers or quantity",
 		})
 		return
 	}
+	seen := make(map[string]bool, len(request.SeatNumbers))
+	for _, seatNo := range request.SeatNumbers {
+		if seen[seatNo] {
+			c.JSON(http.StatusBadRequest, &models.APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Seat %s is listed more than once", seatNo),
+			})
+			return
+		}
+		seen[seatNo] = true
+	}
+	request.CouponCode = strings.ToUpper(strings.TrimSpace(request.CouponCode))
+
+	quote, err := h.eventRepo.QuoteBooking(c.Request.Context(), eventID, &request)
+	if err != nil {
+		var couponErr *repository.InvalidCouponError
+		switch {
+		case errors.As(err, &couponErr):
+			c.JSON(http.StatusBadRequest, &models.APIResponse{
+				Success: false,
+				Error:   couponErr.Error(),
+				Code:    "invalid_coupon",
+				Data:    couponErr,
+			})
+		case contains(err.Error(), "event not found"):
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+		case contains(err.Error(), "unknown seats") || contains(err.Error(), "exceeds the maximum"):
+			c.JSON(http.StatusBadRequest, &models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		default:
+			h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to quote booking")
+			c.JSON(http.StatusInternalServerError, &models.APIResponse{
+				Success: false,
+				Error:   "Failed to quote booking",
+			})
+		}
+		return
+	}
 
-	// Validate price
-	if event.Price < 0 {
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    quote,
+	})
+}
+
+// CountAvailable handles GET /api/events/:id/availability/count[?fast=true]
+func (h *EventHandler) CountAvailable(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, &models.APIResponse{
 			Success: false,
-			Error:   "Price cannot be negative",
+			Error:   "Invalid event ID",
 		})
 		return
 	}
 
-	createdEvent, err := h.eventRepo.CreateEvent(c.Request.Context(), &event)
+	// ?fast=true reads the maintained counter instead of counting tickets
+	var count *models.AvailableCount
+	if c.Query("fast") == "true" {
+		count, err = h.eventRepo.FastAvailableCount(c.Request.Context(), eventID)
+	} else {
+		count, err = h.eventRepo.CountAvailableTickets(c.Request.Context(), eventID)
+	}
 	if err != nil {
-		h.logger.WithError(err).WithFields(logrus.Fields{
-			"event_name":    event.Name,
-			"total_tickets": event.TotalTickets,
-		}).Error("Failed to create event")
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to count available tickets")
 		c.JSON(http.StatusInternalServerError, &models.APIResponse{
 			Success: false,
-			Error:   "Failed to create event",
+			Error:   "Failed to count available tickets",
 		})
 		return
 	}
 
-	h.logger.WithFields(logrus.Fields{
-		"event_id":      createdEvent.ID,
-		"event_name":    createdEvent.Name,
-		"total_tickets": createdEvent.TotalTickets,
-	}).Info("Event created successfully")
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    count,
+	})
+}
 
-	c.JSON(http.StatusCreated, &models.APIResponse{
+// GetSeatMap handles GET /api/events/:id/seatmap
+func (h *EventHandler) GetSeatMap(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		respond(c, http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	seatMap, err := h.eventRepo.GetSeatMap(c.Request.Context(), eventID)
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			respond(c, http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get seat map")
+		respond(c, http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to retrieve seat map",
+		})
+		return
+	}
+
+	respond(c, http.StatusOK, &models.APIResponse{
 		Success: true,
-		Data:    createdEvent,
-		Message: "Event created successfully",
+		Data:    seatMap,
 	})
 }
 
-// GetAvailableTickets handles GET /api/events/:id/tickets
-func (h *EventHandler) GetAvailableTickets(c *gin.Context) {
+// GetSeatMapImage handles GET /api/events/:id/seatmap.png
+func (h *EventHandler) GetSeatMapImage(c *gin.Context) {
 	eventIDStr := c.Param("id")
 	eventID, err := strconv.Atoi(eventIDStr)
 	if err != nil {
@@ -168,31 +930,38 @@ func (h *EventHandler) GetAvailableTickets(c *gin.Context) {
 		return
 	}
 
-	// Get limit from query parameter
-	limitStr := c.DefaultQuery("limit", "50")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 100 {
-		limit = 50
+	scale, err := strconv.Atoi(c.DefaultQuery("scale", "1"))
+	if err != nil || scale < 1 || scale > seating.MaxRenderScale {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Scale must be between 1 and %d", seating.MaxRenderScale),
+		})
+		return
 	}
 
-	tickets, err := h.eventRepo.GetAvailableTickets(c.Request.Context(), eventID, limit)
+	image, err := h.eventRepo.GetSeatMapImage(c.Request.Context(), eventID, scale)
 	if err != nil {
-		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get available tickets")
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to render seat map")
 		c.JSON(http.StatusInternalServerError, &models.APIResponse{
 			Success: false,
-			Error:   "Failed to retrieve available tickets",
+			Error:   "Failed to render seat map",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, &models.APIResponse{
-		Success: true,
-		Data:    tickets,
-	})
+	c.Data(http.StatusOK, "image/png", image)
 }
 
-// GetAllTickets handles GET /api/events/:id/tickets/all
-func (h *EventHandler) GetAllTickets(c *gin.Context) {
+// SuggestSeats handles GET /api/events/:id/seats/suggest
+func (h *EventHandler) SuggestSeats(c *gin.Context) {
 	eventIDStr := c.Param("id")
 	eventID, err := strconv.Atoi(eventIDStr)
 	if err != nil {
@@ -203,26 +972,43 @@ func (h *EventHandler) GetAllTickets(c *gin.Context) {
 		return
 	}
 
-	// Get limit from query parameter
-	limitStr := c.DefaultQuery("limit", "200")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 500 {
-		limit = 200
+	quantity, err := strconv.Atoi(c.DefaultQuery("quantity", "1"))
+	if err != nil || quantity < 1 || quantity > models.MaxTicketsPerBooking {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Quantity must be between 1 and %d", models.MaxTicketsPerBooking),
+		})
+		return
 	}
 
-	tickets, err := h.eventRepo.GetAllTickets(c.Request.Context(), eventID, limit)
+	suggestion, err := h.eventRepo.SuggestSeats(c.Request.Context(), eventID, quantity)
 	if err != nil {
-		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to get all tickets")
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+		if contains(err.Error(), "not enough available seats") {
+			c.JSON(http.StatusConflict, &models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to suggest seats")
 		c.JSON(http.StatusInternalServerError, &models.APIResponse{
 			Success: false,
-			Error:   "Failed to retrieve all tickets",
+			Error:   "Failed to suggest seats",
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, &models.APIResponse{
 		Success: true,
-		Data:    tickets,
+		Data:    suggestion,
 	})
 }
 
@@ -239,9 +1025,31 @@ func (h *EventHandler) LockSeat(c *gin.Context) {
 	}
 
 	seatNo := c.Param("seatNo")
-	userSession := c.GetHeader("X-Session-ID") // You'll need to send this from UI
-	if userSession == "" {
-		userSession = "anonymous"
+	if err := seating.CheckLabel(seatNo); err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid seat label",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// A label the event's own scheme can't produce is rejected before LockSeat
+	// opens a transaction. The event usually comes from the cache; if it can't
+	// be read here, LockSeat reports why.
+	if event, err := h.eventRepo.GetEvent(c.Request.Context(), eventID); err == nil &&
+		!seating.MatchesFormat(event.SeatLabelFormat, seatNo) {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid seat label",
+			Message: fmt.Sprintf("seats for this event are labelled %s", event.SeatLabelFormat),
+		})
+		return
+	}
+
+	userSession, ok := requestSession(c, h.config.App.RequireSession)
+	if !ok {
+		return
 	}
 
 	err = h.eventRepo.LockSeat(c.Request.Context(), eventID, seatNo, userSession)
@@ -251,9 +1059,28 @@ func (h *EventHandler) LockSeat(c *gin.Context) {
 			"seat_no":  seatNo,
 		}).Error("Failed to lock seat")
 
+		if respondLockLimit(c, err) || respondSalesPaused(c, err) {
+			return
+		}
+
+		var holdErr *repository.MaxHoldDurationError
+		if errors.As(err, &holdErr) {
+			c.JSON(http.StatusConflict, &models.APIResponse{
+				Success: false,
+				Error:   holdErr.Error(),
+				Code:    "max_hold_duration",
+				Data:    holdErr,
+			})
+			return
+		}
+
 		statusCode := http.StatusConflict
-		if contains(err.Error(), "not found") {
+		if contains(err.Error(), "invalid seat label") {
+			statusCode = http.StatusBadRequest
+		} else if contains(err.Error(), "not found") {
 			statusCode = http.StatusNotFound
+		} else if contains(err.Error(), "already ended") {
+			statusCode = http.StatusBadRequest
 		}
 
 		c.JSON(statusCode, &models.APIResponse{
@@ -269,6 +1096,56 @@ func (h *EventHandler) LockSeat(c *gin.Context) {
 	})
 }
 
+// ToggleSales handles POST /api/events/:id/sales/toggle
+func (h *EventHandler) ToggleSales(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	// The body is optional: {"open": false} pauses, {"open": true} resumes,
+	// and no body flips the current state
+	var request models.SalesToggleRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	salesOpen, err := h.eventRepo.SetSalesOpen(c.Request.Context(), eventID, request.Open, adminActor(c))
+	if err != nil {
+		if contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, &models.APIResponse{
+				Success: false,
+				Error:   "Event not found",
+			})
+			return
+		}
+
+		h.logger.WithError(err).WithField("event_id", eventID).Error("Failed to toggle event sales")
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to update sales state",
+		})
+		return
+	}
+
+	message := "Sales resumed"
+	if !salesOpen {
+		message = "Sales paused"
+	}
+
+	c.JSON(http.StatusOK, &models.APIResponse{
+		Success: true,
+		Data:    &models.SalesState{EventID: eventID, SalesOpen: salesOpen},
+		Message: message,
+	})
+}
+
 // UnlockSeat handles POST /api/events/:id/seats/:seatNo/unlock
 func (h *EventHandler) UnlockSeat(c *gin.Context) {
 	eventIDStr := c.Param("id")
@@ -283,7 +1160,12 @@ func (h *EventHandler) UnlockSeat(c *gin.Context) {
 
 	seatNo := c.Param("seatNo")
 
-	err = h.eventRepo.UnlockSeat(c.Request.Context(), eventID, seatNo)
+	userSession, ok := requestSession(c, h.config.App.RequireSession)
+	if !ok {
+		return
+	}
+
+	err = h.eventRepo.UnlockSeat(c.Request.Context(), eventID, seatNo, userSession)
 	if err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
 			"event_id": eventID,
@@ -302,3 +1184,41 @@ func (h *EventHandler) UnlockSeat(c *gin.Context) {
 		Message: "Seat unlocked",
 	})
 }
+
+// eventFilter reads the optional listing filters from the query string
+func eventFilter(c *gin.Context) (models.EventFilter, error) {
+	var filter models.EventFilter
+	var err error
+
+	filter.CreatedAfter, filter.CreatedBefore, err = createdRange(c)
+	return filter, err
+}
+
+// createdRange reads ?created_after= (inclusive) and ?created_before= (exclusive)
+func createdRange(c *gin.Context) (after, before *time.Time, err error) {
+	if after, err = queryTime(c, "created_after"); err != nil {
+		return nil, nil, err
+	}
+	if before, err = queryTime(c, "created_before"); err != nil {
+		return nil, nil, err
+	}
+	if after != nil && before != nil && !after.Before(*before) {
+		return nil, nil, fmt.Errorf("created_after must be before created_before")
+	}
+	return after, before, nil
+}
+
+// queryTime parses an optional RFC 3339 timestamp or YYYY-MM-DD date (midnight UTC) query parameter
+func queryTime(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or YYYY-MM-DD date, got %q", name, value)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// validTestEvent returns an event validateEvent accepts
+func validTestEvent() *models.Event {
+	start := time.Now().Add(24 * time.Hour)
+	return &models.Event{
+		Name:         "Concert",
+		Venue:        "Main Hall",
+		StartTime:    start,
+		EndTime:      start.Add(2 * time.Hour),
+		TotalTickets: 100,
+		Price:        2500,
+		Currency:     "USD",
+	}
+}
+
+func TestNormalizeEventTextLengths(t *testing.T) {
+	url := func(n int) string {
+		prefix := "https://example.com/"
+		return prefix + strings.Repeat("a", n-len(prefix))
+	}
+
+	tests := []struct {
+		name   string
+		modify func(e *models.Event)
+		field  string // field expected to be rejected, or "" for none
+	}{
+		{"name at limit", func(e *models.Event) { e.Name = strings.Repeat("n", 200) }, ""},
+		{"name over limit", func(e *models.Event) { e.Name = strings.Repeat("n", 201) }, "name"},
+		{"multibyte name at limit", func(e *models.Event) { e.Name = strings.Repeat("é", 200) }, ""},
+		{"multibyte name over limit", func(e *models.Event) { e.Name = strings.Repeat("é", 201) }, "name"},
+		{"emoji name at limit", func(e *models.Event) { e.Name = strings.Repeat("🎫", 200) }, ""},
+		{"padded name at limit", func(e *models.Event) { e.Name = "  " + strings.Repeat("n", 200) + "\t" }, ""},
+		{"venue at limit", func(e *models.Event) { e.Venue = strings.Repeat("v", 200) }, ""},
+		{"venue over limit", func(e *models.Event) { e.Venue = strings.Repeat("v", 201) }, "venue"},
+		{"multibyte venue at limit", func(e *models.Event) { e.Venue = strings.Repeat("日", 200) }, ""},
+		{"multibyte venue over limit", func(e *models.Event) { e.Venue = strings.Repeat("日", 201) }, "venue"},
+		{"description at limit", func(e *models.Event) { e.Description = strings.Repeat("d", 5000) }, ""},
+		{"description over limit", func(e *models.Event) { e.Description = strings.Repeat("d", 5001) }, "description"},
+		{"multibyte description at limit", func(e *models.Event) { e.Description = strings.Repeat("ü", 5000) }, ""},
+		{"multibyte description over limit", func(e *models.Event) { e.Description = strings.Repeat("ü", 5001) }, "description"},
+		{"image url at limit", func(e *models.Event) { e.ImageURL = url(2048) }, ""},
+		{"image url over limit", func(e *models.Event) { e.ImageURL = url(2049) }, "image_url"},
+		{"banner url at limit", func(e *models.Event) { e.BannerURL = url(2048) }, ""},
+		{"banner url over limit", func(e *models.Event) { e.BannerURL = url(2049) }, "banner_url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := validTestEvent()
+			tt.modify(event)
+			fields := normalizeEventText(event)
+
+			if tt.field == "" {
+				if len(fields) != 0 {
+					t.Errorf("fields = %v, want none", fields)
+				}
+				return
+			}
+			if len(fields) != 1 || !strings.HasPrefix(fields[tt.field], "must be at most") {
+				t.Errorf("fields = %v, want only a length error on %s", fields, tt.field)
+			}
+		})
+	}
+}
+
+func TestNormalizeEventTextContent(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(e *models.Event)
+		fields map[string]string
+	}{
+		{"blank name", func(e *models.Event) { e.Name = "   " }, map[string]string{"name": "is required"}},
+		{"missing venue", func(e *models.Event) { e.Venue = "" }, map[string]string{"venue": "is required"}},
+		{"newline in name", func(e *models.Event) { e.Name = "Con\ncert" },
+			map[string]string{"name": "must not contain control characters"}},
+		{"newline in description", func(e *models.Event) { e.Description = "Line one\nLine two" }, map[string]string{}},
+		{"nul in description", func(e *models.Event) { e.Description = "bad\x00" },
+			map[string]string{"description": "must not contain control characters"}},
+		{"non-http image url", func(e *models.Event) { e.ImageURL = "javascript:alert(1)" },
+			map[string]string{"image_url": "must be an http or https URL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := validTestEvent()
+			tt.modify(event)
+			fields := normalizeEventText(event)
+
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("fields = %v, want %v", fields, tt.fields)
+			}
+			for field, message := range tt.fields {
+				if fields[field] != message {
+					t.Errorf("fields[%s] = %q, want %q", field, fields[field], message)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeEventTextTrims(t *testing.T) {
+	event := validTestEvent()
+	event.Name = "  Concert \n"
+	event.Venue = "\tMain Hall "
+	event.Description = "\n About the show \n"
+
+	if fields := normalizeEventText(event); len(fields) != 0 {
+		t.Fatalf("fields = %v, want none", fields)
+	}
+	if event.Name != "Concert" || event.Venue != "Main Hall" || event.Description != "About the show" {
+		t.Errorf("trimmed to %q, %q, %q", event.Name, event.Venue, event.Description)
+	}
+}
+
+func TestValidateEventFieldErrors(t *testing.T) {
+	h := &EventHandler{config: &config.Config{App: config.AppConfig{DefaultCurrency: "USD"}}}
+
+	if resp := h.validateEvent(validTestEvent(), false); resp != nil {
+		t.Fatalf("valid event rejected: %+v", resp)
+	}
+
+	event := validTestEvent()
+	event.Name = strings.Repeat("é", 201)
+	event.Venue = ""
+	resp := h.validateEvent(event, false)
+	if resp == nil {
+		t.Fatal("event with invalid name and venue accepted")
+	}
+	if resp.Code != "validation_failed" {
+		t.Errorf("code = %q, want validation_failed", resp.Code)
+	}
+	fields, ok := resp.Data.(map[string]string)
+	if !ok || fields["name"] == "" || fields["venue"] == "" {
+		t.Errorf("data = %#v, want errors for name and venue", resp.Data)
+	}
+}
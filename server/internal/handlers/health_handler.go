@@ -1,21 +1,33 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/milinddethe15/ticket-booking/internal/db"
 	"github.com/milinddethe15/ticket-booking/internal/models"
 )
 
 const AppVersion = "1.0.0"
 
+// deepCheckTimeout bounds the whole /health/deep run so a wedged database
+// can't hang the probe
+const deepCheckTimeout = 3 * time.Second
+
+// requiredTables are queried by /health/deep to catch missing migrations
+var requiredTables = []string{"events", "tickets", "bookings", "holds"}
+
 // HealthHandler handles health check endpoints
-type HealthHandler struct{}
+type HealthHandler struct {
+	db *db.DB
+}
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(database *db.DB) *HealthHandler {
+	return &HealthHandler{db: database}
 }
 
 // Health handles GET /health
@@ -27,6 +39,67 @@ func (h *HealthHandler) Health(c *gin.Context) {
 	})
 }
 
+// DeepHealth handles GET /health/deep. Unlike /health it talks to the
+// database and queries every required table, returning 503 if any check fails.
+func (h *HealthHandler) DeepHealth(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), deepCheckTimeout)
+	defer cancel()
+
+	checks := make(map[string]models.HealthCheck, len(requiredTables)+2)
+	healthy := true
+
+	record := func(name string, fn func() error) {
+		start := time.Now()
+		err := fn()
+		check := models.HealthCheck{
+			Status:     "ok",
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			check.Status = "failed"
+			check.Error = err.Error()
+			healthy = false
+		}
+		checks[name] = check
+	}
+
+	record("database", func() error {
+		return h.db.PingContext(ctx)
+	})
+	if h.db.HasReplica() {
+		record("database:replica", func() error {
+			return h.db.Reader().PingContext(ctx)
+		})
+	}
+	for _, table := range requiredTables {
+		// Table names come from the fixed list above, never from the request
+		query := "SELECT 1 FROM " + table + " LIMIT 1"
+		record("table:"+table, func() error {
+			var one int
+			err := h.db.QueryRowContext(ctx, query).Scan(&one)
+			if err == sql.ErrNoRows {
+				// An empty table is still a healthy one
+				return nil
+			}
+			return err
+		})
+	}
+
+	response := &models.HealthResponse{
+		Status:    "healthy",
+		Timestamp: time.Now(),
+		Version:   AppVersion,
+		Checks:    checks,
+	}
+	statusCode := http.StatusOK
+	if !healthy {
+		response.Status = "unhealthy"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.JSON(statusCode, response)
+}
+
 // Ready handles GET /ready for readiness probe
 func (h *HealthHandler) Ready(c *gin.Context) {
 	// In a real application, you would check database connectivity,
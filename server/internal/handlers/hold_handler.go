@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/repository"
+)
+
+type HoldHandler struct {
+	holdRepo *repository.HoldRepository
+	logger   *logrus.Logger
+	config   *config.Config
+}
+
+func NewHoldHandler(holdRepo *repository.HoldRepository, logger *logrus.Logger, cfg *config.Config) *HoldHandler {
+	return &HoldHandler{
+		holdRepo: holdRepo,
+		logger:   logger,
+		config:   cfg,
+	}
+}
+
+// CreateHold handles POST /api/events/:id/hold
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "Invalid event ID",
+		})
+		return
+	}
+
+	var request models.HoldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.WithError(err).Error("Invalid hold request")
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err, &request))
+		return
+	}
+
+	seen := make(map[string]bool, len(request.Seats))
+	for _, seatNo := range request.Seats {
+		if seen[seatNo] {
+			c.JSON(http.StatusBadRequest, &models.APIResponse{
+				Success: false,
+				Error:   "Duplicate seat in hold request: " + seatNo,
+			})
+			return
+		}
+		seen[seatNo] = true
+	}
+
+	userSession, ok := requestSession(c, h.config.App.RequireSession)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdRepo.CreateHold(c.Request.Context(), eventID, request.Seats, userSession)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"event_id": eventID,
+			"seats":    request.Seats,
+		}).Error("Failed to create hold")
+
+		if respondLockLimit(c, err) || respondSalesPaused(c, err) {
+			return
+		}
+
+		statusCode := http.StatusInternalServerError
+		if contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if contains(err.Error(), "no longer available") {
+			statusCode = http.StatusConflict
+		} else if contains(err.Error(), "already ended") {
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, &models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, &models.APIResponse{
+		Success: true,
+		Data:    hold,
+		Message: "Seats held. Use the hold_id to complete the booking before it expires.",
+	})
+}
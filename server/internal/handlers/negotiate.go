@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/protoenc"
+)
+
+// respond writes response as JSON, or as protobuf (proto/ticket_booking.proto)
+// when the client prefers application/x-protobuf. Data without a protobuf
+// form is sent as JSON regardless.
+func respond(c *gin.Context, status int, response *models.APIResponse) {
+	// Added rather than set, to keep the Vary the compression middleware sent
+	c.Writer.Header().Add("Vary", "Accept")
+
+	if c.NegotiateFormat(binding.MIMEJSON, binding.MIMEPROTOBUF) == binding.MIMEPROTOBUF {
+		if body, ok := protoenc.MarshalResponse(response); ok {
+			c.Data(status, binding.MIMEPROTOBUF, body)
+			return
+		}
+	}
+
+	c.JSON(status, response)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPprofRoutes mounts the net/http/pprof handlers on the given group,
+// which must be mounted at /debug/pprof for the index links to resolve
+func RegisterPprofRoutes(rg *gin.RouterGroup) {
+	rg.GET("/", gin.WrapF(pprof.Index))
+	rg.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	rg.GET("/profile", gin.WrapF(pprof.Profile))
+	rg.GET("/symbol", gin.WrapF(pprof.Symbol))
+	rg.POST("/symbol", gin.WrapF(pprof.Symbol))
+	rg.GET("/trace", gin.WrapF(pprof.Trace))
+	// Named profiles: allocs, block, goroutine, heap, mutex, threadcreate
+	rg.GET("/:name", gin.WrapF(pprof.Index))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/repository"
+)
+
+// sessionCookieName carries the server-issued session for clients that don't
+// send X-Session-ID
+const sessionCookieName = "ticket_session"
+
+// requestSession identifies the caller for seat locks and holds: the
+// X-Session-ID header, else the session cookie. Without either it rejects the
+// request with 400 when sessions are required, and otherwise issues a new
+// session cookie so anonymous callers never share a session. It reports false
+// once a response has been written.
+func requestSession(c *gin.Context, requireSession bool) (string, bool) {
+	if session := c.GetHeader("X-Session-ID"); session != "" {
+		return session, true
+	}
+	if session, err := c.Cookie(sessionCookieName); err == nil && session != "" {
+		return session, true
+	}
+
+	if requireSession {
+		c.JSON(http.StatusBadRequest, &models.APIResponse{
+			Success: false,
+			Error:   "X-Session-ID header is required",
+			Code:    "session_required",
+		})
+		return "", false
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		c.JSON(http.StatusInternalServerError, &models.APIResponse{
+			Success: false,
+			Error:   "Failed to create session",
+		})
+		return "", false
+	}
+	session := hex.EncodeToString(buf)
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    session,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return session, true
+}
+
+// existingSession returns the caller's session without issuing one; empty if
+// the request carries none
+func existingSession(c *gin.Context) string {
+	if session := c.GetHeader("X-Session-ID"); session != "" {
+		return session
+	}
+	session, _ := c.Cookie(sessionCookieName)
+	return session
+}
+
+// respondSalesPaused answers 403 when err refuses new seats because the
+// event's sales are paused
+func respondSalesPaused(c *gin.Context, err error) bool {
+	var pausedErr *repository.SalesPausedError
+	if !errors.As(err, &pausedErr) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, &models.APIResponse{
+		Success: false,
+		Error:   "Sales are paused for this event",
+		Code:    "sales_paused",
+		Data:    pausedErr,
+	})
+	return true
+}
+
+// respondLockLimit answers 429 when err is a per-session lock limit rejection
+func respondLockLimit(c *gin.Context, err error) bool {
+	var limitErr *repository.SessionLockLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	c.JSON(http.StatusTooManyRequests, &models.APIResponse{
+		Success: false,
+		Error:   limitErr.Error(),
+		Code:    "session_lock_limit",
+		Data:    limitErr,
+	})
+	return true
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+func init() {
+	// Report fields by their JSON names so errors match what clients sent
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// bindingErrorResponse turns a ShouldBindJSON error into a 400 body. Validation
+// and type errors are reported per field in Data, keyed by JSON field name;
+// anything else (e.g. malformed JSON) falls back to the raw message.
+func bindingErrorResponse(err error, obj interface{}) *models.APIResponse {
+	response := &models.APIResponse{
+		Success: false,
+		Error:   "Invalid request format",
+	}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make(map[string]string, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields[fieldPath(fe)] = validationMessage(fe, obj)
+		}
+		response.Code = "validation_failed"
+		response.Data = fields
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		response.Code = "validation_failed"
+		response.Data = map[string]string{field: "must be a " + jsonTypeName(typeErr.Type)}
+	default:
+		response.Message = err.Error()
+	}
+
+	return response
+}
+
+// fieldPath strips the struct name from the namespace: "BookingRequest.quantity" -> "quantity"
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+func validationMessage(fe validator.FieldError, obj interface{}) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min", "max":
+		return boundMessage(fe, obj)
+	}
+	return fmt.Sprintf("failed %q validation", fe.Tag())
+}
+
+func boundMessage(fe validator.FieldError, obj interface{}) string {
+	bound := "at least " + fe.Param()
+	if fe.Tag() == "max" {
+		bound = "at most " + fe.Param()
+	}
+	if lower, upper, ok := fieldBounds(obj, fe.StructField()); ok {
+		bound = "between " + lower + " and " + upper
+	}
+
+	switch fe.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("must have %s items", bound)
+	case reflect.String:
+		return fmt.Sprintf("must be %s characters", bound)
+	}
+	return "must be " + bound
+}
+
+// fieldBounds finds a top-level field's min and max binding rules so both
+// ends of the range can be reported together
+func fieldBounds(obj interface{}, structField string) (string, string, bool) {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", "", false
+	}
+
+	field, ok := t.FieldByName(structField)
+	if !ok {
+		return "", "", false
+	}
+
+	var lower, upper string
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		if rule == "dive" {
+			break
+		}
+		if v, ok := strings.CutPrefix(rule, "min="); ok {
+			lower = v
+		} else if v, ok := strings.CutPrefix(rule, "max="); ok {
+			upper = v
+		}
+	}
+	return lower, upper, lower != "" && upper != ""
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "whole number"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "string"
+}
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// incompressibleTypes are already compressed, or streamed where buffering
+// would hold events back
+var incompressibleTypes = map[string]bool{
+	"text/event-stream":        true,
+	"application/gzip":         true,
+	"application/zip":          true,
+	"application/octet-stream": true,
+	"image/png":                true,
+	"image/jpeg":               true,
+	"image/gif":                true,
+	"image/webp":               true,
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+var errResponseClosed = errors.New("response already finished")
+
+// Gzip compresses responses for clients that accept gzip. The body is buffered
+// until it reaches minSize bytes, so small responses go out as they are, as do
+// responses that already have a Content-Encoding or an incompressible content
+// type. Paths starting with one of excludePrefixes, e.g. streaming endpoints,
+// are never touched.
+func Gzip(minSize int, excludePrefixes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range excludePrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &gzipResponseWriter{ResponseWriter: original, minSize: minSize}
+		c.Writer = writer
+		// A panic leaves the partial body unsent so the recovery middleware can
+		// answer on the original writer
+		defer func() { c.Writer = original }()
+
+		c.Next()
+		writer.finish()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter holds the body back until it knows whether compressing is
+// worth it. Writes are serialised because a handler can still be running when
+// RequestTimeout has given up on it and answered the request.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minSize int
+
+	mu       sync.Mutex
+	buf      []byte
+	decided  bool
+	gz       *gzip.Writer
+	finished bool
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.finished {
+		return 0, errResponseClosed
+	}
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written also counts a body that is still buffered
+func (w *gzipResponseWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends what has been written so far, deciding on compression early
+func (w *gzipResponseWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.finished {
+		return
+	}
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide picks compression for the buffered body and writes it out
+func (w *gzipResponseWriter) decide() error {
+	w.decided = true
+	header := w.Header()
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" && len(w.buf) > 0 {
+		contentType = http.DetectContentType(w.buf)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if len(w.buf) >= w.minSize && len(w.buf) > 0 &&
+		header.Get("Content-Encoding") == "" && !incompressibleTypes[mediaType] {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// finish sends any buffered body and closes the gzip stream
+func (w *gzipResponseWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.finished {
+		return
+	}
+	if !w.decided {
+		w.decide()
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+	w.finished = true
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testMinSize = 1024
+
+// gzipRouter serves body as JSON from every path through Gzip, with /stream
+// excluded and /encoded answering with its own Content-Encoding
+func gzipRouter(body string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Gzip(testMinSize, []string{"/stream"}))
+	router.GET("/encoded", func(c *gin.Context) {
+		c.Header("Content-Encoding", "br")
+		c.Data(http.StatusOK, "application/json", []byte(body))
+	})
+	router.NoRoute(func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(body))
+	})
+	return router
+}
+
+func serveGzip(router *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGzipRoundTrip(t *testing.T) {
+	body := `{"data":"` + strings.Repeat("ticket ", testMinSize) + `"}`
+	w := serveGzip(gzipRouter(body), "/api/v1/events", "br, gzip;q=0.8")
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+	if w.Body.Len() >= len(body) {
+		t.Errorf("compressed body is %d bytes, original %d", w.Body.Len(), len(body))
+	}
+
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+	if string(decoded) != body {
+		t.Errorf("decompressed body differs from the original (%d vs %d bytes)", len(decoded), len(body))
+	}
+}
+
+func TestGzipPassthrough(t *testing.T) {
+	large := `{"data":"` + strings.Repeat("ticket ", testMinSize) + `"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{"below min size", `{"ok":true}`, "/api/v1/events", "gzip", ""},
+		{"excluded prefix", large, "/stream/events/1", "gzip", ""},
+		{"gzip refused with q=0", large, "/api/v1/events", "gzip;q=0", ""},
+		{"wildcard refused with q=0", large, "/api/v1/events", "*;q=0", ""},
+		{"no accept-encoding", large, "/api/v1/events", "", ""},
+		{"existing content-encoding", large, "/encoded", "gzip", "br"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveGzip(gzipRouter(tt.body), tt.path, tt.acceptEncoding)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if !bytes.Equal(w.Body.Bytes(), []byte(tt.body)) {
+				t.Errorf("body was modified (%d bytes, want %d)", w.Body.Len(), len(tt.body))
+			}
+		})
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP", true},
+		{"deflate, gzip", true},
+		{"gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"br, deflate", false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// Drainer lets the server stop taking work before it stops listening. Once
+// Start is called, new requests get 503 with Connection: close so clients and
+// load balancers retry elsewhere, while requests already past the middleware
+// run to completion.
+type Drainer struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+}
+
+func NewDrainer() *Drainer {
+	return &Drainer{}
+}
+
+// Start begins draining and returns how many requests were in flight
+func (d *Drainer) Start() int64 {
+	d.draining.Store(true)
+	return d.inFlight.Load()
+}
+
+// Draining reports whether Start has been called
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// InFlight is the number of requests currently being handled
+func (d *Drainer) InFlight() int64 {
+	return d.inFlight.Load()
+}
+
+// Middleware rejects requests that arrive after Start
+func (d *Drainer) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d.draining.Load() {
+			c.Header("Connection", "close")
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, &models.APIResponse{
+				Success: false,
+				Error:   "Server is shutting down",
+				Code:    "shutting_down",
+			})
+			return
+		}
+
+		d.inFlight.Add(1)
+		defer d.inFlight.Add(-1)
+		c.Next()
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxLatencySamples bounds the memory one route can use per window; busier
+// routes are reservoir-sampled, which keeps the percentiles representative
+const maxLatencySamples = 4096
+
+// LatencyTracker collects request latencies per route and periodically logs
+// their p50/p95/p99 as a lightweight SLO signal. Each report covers only the
+// requests since the previous one.
+type LatencyTracker struct {
+	logger *logrus.Logger
+
+	mu     sync.Mutex
+	routes map[string]*latencyWindow
+	since  time.Time
+}
+
+type latencyWindow struct {
+	samples []time.Duration
+	count   int
+	max     time.Duration
+}
+
+// RouteLatency summarises one route over a reporting window
+type RouteLatency struct {
+	Route string
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func NewLatencyTracker(logger *logrus.Logger) *LatencyTracker {
+	return &LatencyTracker{
+		logger: logger,
+		routes: make(map[string]*latencyWindow),
+		since:  time.Now(),
+	}
+}
+
+// Middleware records how long each matched route took. Requests that match no
+// route are skipped so scanners can't grow the map with arbitrary paths.
+func (t *LatencyTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		if c.FullPath() == "" {
+			return
+		}
+		t.record(RouteKey(c.Request.Method, c.FullPath()), time.Since(start))
+	}
+}
+
+func (t *LatencyTracker) record(route string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	window, ok := t.routes[route]
+	if !ok {
+		window = &latencyWindow{}
+		t.routes[route] = window
+	}
+
+	window.count++
+	if latency > window.max {
+		window.max = latency
+	}
+	if len(window.samples) < maxLatencySamples {
+		window.samples = append(window.samples, latency)
+	} else if i := rand.Intn(window.count); i < maxLatencySamples {
+		window.samples[i] = latency
+	}
+}
+
+// Snapshot returns the percentiles for the current window and starts a new one
+func (t *LatencyTracker) Snapshot() ([]RouteLatency, time.Duration) {
+	t.mu.Lock()
+	routes := t.routes
+	since := t.since
+	t.routes = make(map[string]*latencyWindow)
+	t.since = time.Now()
+	t.mu.Unlock()
+
+	report := make([]RouteLatency, 0, len(routes))
+	for route, window := range routes {
+		sort.Slice(window.samples, func(i, j int) bool { return window.samples[i] < window.samples[j] })
+		report = append(report, RouteLatency{
+			Route: route,
+			Count: window.count,
+			P50:   percentile(window.samples, 50),
+			P95:   percentile(window.samples, 95),
+			P99:   percentile(window.samples, 99),
+			Max:   window.max,
+		})
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Route < report[j].Route })
+
+	return report, time.Since(since)
+}
+
+// Run logs a report every interval until ctx is cancelled
+func (t *LatencyTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, window := t.Snapshot()
+		for _, route := range report {
+			t.logger.WithFields(logrus.Fields{
+				"route":  route.Route,
+				"count":  route.Count,
+				"p50_ms": milliseconds(route.P50),
+				"p95_ms": milliseconds(route.P95),
+				"p99_ms": milliseconds(route.P99),
+				"max_ms": milliseconds(route.Max),
+				"window": window.Round(time.Second).String(),
+			}).Info("Route latency")
+		}
+	}
+}
+
+// percentile uses the nearest-rank method on sorted samples
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -2,8 +2,15 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +18,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/requestid"
 )
 
 // RateLimiter creates a rate limiting middleware
@@ -19,11 +27,7 @@ func RateLimiter(rps int) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		if !limiter.AllowN(time.Now(), 1) {
-			c.JSON(http.StatusTooManyRequests, &models.APIResponse{
-				Success: false,
-				Error:   "Rate limit exceeded. Please try again later.",
-			})
-			c.Abort()
+			rateLimitExceeded(c)
 			return
 		}
 
@@ -83,28 +87,74 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
-// ErrorHandler middleware for centralized error handling
-func ErrorHandler() gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			c.JSON(http.StatusInternalServerError, &models.APIResponse{
-				Success: false,
-				Error:   "Internal server error",
-				Message: err,
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, &models.APIResponse{
+// ErrorHandler middleware for centralized error handling. A panic is logged
+// with its value, stack trace and request ID and answered with a generic 500;
+// details never reach the client.
+func ErrorHandler(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			logger.WithFields(logrus.Fields{
+				"request_id": c.GetString("RequestID"),
+				"method":     c.Request.Method,
+				"path":       c.Request.URL.Path,
+				"panic":      fmt.Sprint(recovered),
+				"stack":      string(debug.Stack()),
+			}).Error("Recovered from panic")
+
+			// The client went away mid-response; there is nobody to answer
+			if err, ok := recovered.(error); ok && isBrokenPipe(err) {
+				c.Abort()
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &models.APIResponse{
 				Success: false,
 				Error:   "Internal server error",
 			})
-		}
-		c.Abort()
-	})
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenPipe reports whether err comes from writing to a closed connection
+func isBrokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if !errors.As(opErr, &syscallErr) {
+		return false
+	}
+	msg := strings.ToLower(syscallErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
 }
 
-// RequestTimeout middleware to prevent long-running requests
-func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+// RequestTimeout middleware to prevent long-running requests. Routes listed in
+// overrides, keyed by RouteKey, get their own timeout instead of the default;
+// this has to be decided here because a nested timeout can only shorten the
+// deadline, never extend it. Paths starting with one of exemptPrefixes, e.g.
+// profiling endpoints that run for as long as they are asked to, get none.
+func RequestTimeout(timeout time.Duration, overrides map[string]time.Duration, exemptPrefixes []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		for _, prefix := range exemptPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		timeout := timeout
+		if d, ok := overrides[RouteKey(c.Request.Method, c.FullPath())]; ok {
+			timeout = d
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 
@@ -129,22 +179,30 @@ func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
 		case p := <-panicChan:
 			panic(p)
 		case <-ctx.Done():
-			c.JSON(http.StatusRequestTimeout, &models.APIResponse{
+			c.AbortWithStatusJSON(http.StatusRequestTimeout, &models.APIResponse{
 				Success: false,
 				Error:   "Request timeout",
+				Code:    "request_timeout",
 			})
-			c.Abort()
 		}
 	}
 }
 
+// RouteKey identifies a route for RequestTimeout overrides, e.g. "POST /api/v1/events"
+func RouteKey(method, path string) string {
+	return method + " " + path
+}
+
 // Security headers middleware
 func Security() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("X-Content-Type-Options", "nosniff")
 		c.Header("X-Frame-Options", "DENY")
 		c.Header("X-XSS-Protection", "1; mode=block")
-		c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		// HSTS is only honoured over HTTPS, whether terminated here or by a proxy
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		}
 		c.Header("Content-Security-Policy", "default-src 'self'")
 		c.Next()
 	}
@@ -156,24 +214,101 @@ func RequestID() gin.HandlerFunc {
 		requestID := generateRequestID()
 		c.Header("X-Request-ID", requestID)
 		c.Set("RequestID", requestID)
+		// Also on the request context, for code below the handlers
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
 		c.Next()
 	}
 }
 
-// Pagination middleware to parse pagination parameters
-func Pagination() gin.HandlerFunc {
+// AdminAuth middleware guards admin routes with a static bearer token
+func AdminAuth(apiKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		page := c.DefaultQuery("page", "1")
-		limit := c.DefaultQuery("limit", "20")
+		if apiKey == "" {
+			c.JSON(http.StatusForbidden, &models.APIResponse{
+				Success: false,
+				Error:   "Admin API is disabled",
+			})
+			c.Abort()
+			return
+		}
 
-		pageInt, err := strconv.Atoi(page)
-		if err != nil || pageInt < 1 {
-			pageInt = 1
+		if !HasAdminKey(c, apiKey) {
+			c.JSON(http.StatusUnauthorized, &models.APIResponse{
+				Success: false,
+				Error:   "Unauthorized",
+			})
+			c.Abort()
+			return
 		}
 
-		limitInt, err := strconv.Atoi(limit)
-		if err != nil || limitInt < 1 || limitInt > 100 {
-			limitInt = 20
+		c.Next()
+	}
+}
+
+// HasAdminKey reports whether the request carries the admin bearer token, for
+// public routes that unlock extra options for operators. It is always false
+// when no admin key is configured.
+func HasAdminKey(c *gin.Context, apiKey string) bool {
+	if apiKey == "" {
+		return false
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) == 1
+}
+
+// GateAuth guards gate staff endpoints. Staff use the gate key so they don't
+// need admin access; the admin key is accepted as well. With neither key
+// configured the endpoints are disabled.
+func GateAuth(gateKey, adminKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if gateKey == "" && adminKey == "" {
+			c.JSON(http.StatusForbidden, &models.APIResponse{
+				Success: false,
+				Error:   "Gate API is disabled",
+			})
+			c.Abort()
+			return
+		}
+
+		token := []byte(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		for _, key := range []string{gateKey, adminKey} {
+			if key != "" && subtle.ConstantTimeCompare(token, []byte(key)) == 1 {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusUnauthorized, &models.APIResponse{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		c.Abort()
+	}
+}
+
+// Pagination middleware to parse pagination parameters. Invalid values fall
+// back to page 1 and the default size, unless strict is set or the request
+// sends ?strict_pagination=true, in which case they are rejected with 400.
+func Pagination(defaultSize, maxSize int, strict bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strict := StrictPagination(c, strict)
+
+		pageInt := 1
+		if page, ok := c.GetQuery("page"); ok {
+			parsed, err := strconv.Atoi(page)
+			switch {
+			case err == nil && parsed >= 1:
+				pageInt = parsed
+			case strict:
+				respondInvalidPagination(c, fmt.Sprintf("page must be a positive integer, got %q", page))
+				return
+			}
+		}
+
+		limitInt, err := ParseLimit(c, defaultSize, maxSize, strict)
+		if err != nil {
+			respondInvalidPagination(c, err.Error())
+			return
 		}
 
 		offset := (pageInt - 1) * limitInt
@@ -185,6 +320,38 @@ func Pagination() gin.HandlerFunc {
 	}
 }
 
+// StrictPagination reports whether invalid page or limit parameters should be
+// rejected: always when configured, otherwise when the request asks for it
+func StrictPagination(c *gin.Context, configured bool) bool {
+	return configured || c.Query("strict_pagination") == "true"
+}
+
+// ParseLimit reads ?limit. A missing limit is defaultSize; an invalid one is
+// an error in strict mode and defaultSize otherwise.
+func ParseLimit(c *gin.Context, defaultSize, maxSize int, strict bool) (int, error) {
+	raw, ok := c.GetQuery("limit")
+	if !ok {
+		return defaultSize, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxSize {
+		if strict {
+			return 0, fmt.Errorf("limit must be between 1 and %d, got %q", maxSize, raw)
+		}
+		return defaultSize, nil
+	}
+	return limit, nil
+}
+
+func respondInvalidPagination(c *gin.Context, reason string) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, &models.APIResponse{
+		Success: false,
+		Error:   "Invalid pagination parameters",
+		Code:    "invalid_pagination",
+		Message: reason,
+	})
+}
+
 // Helper function to generate request ID
 func generateRequestID() string {
 	return strconv.FormatInt(time.Now().UnixNano(), 36)
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+func TestErrorHandlerRecoversPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger, hook := test.NewNullLogger()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("RequestID", "req-123")
+		c.Next()
+	})
+	router.Use(ErrorHandler(logger))
+	router.GET("/boom", func(c *gin.Context) {
+		panic("secret connection string")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Success || response.Error != "Internal server error" {
+		t.Errorf("response = %+v, want a generic error", response)
+	}
+	body := w.Body.String()
+	for _, leak := range []string{"secret connection string", "goroutine", ".go:"} {
+		if strings.Contains(body, leak) {
+			t.Errorf("response body leaks %q: %s", leak, body)
+		}
+	}
+
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("panic was not logged")
+	}
+	if entry.Level != logrus.ErrorLevel || entry.Message != "Recovered from panic" {
+		t.Errorf("log entry = %s %q", entry.Level, entry.Message)
+	}
+	if entry.Data["panic"] != "secret connection string" {
+		t.Errorf("logged panic = %v", entry.Data["panic"])
+	}
+	if entry.Data["request_id"] != "req-123" || entry.Data["path"] != "/boom" {
+		t.Errorf("log fields = %v", entry.Data)
+	}
+	if stack, _ := entry.Data["stack"].(string); !strings.Contains(stack, "goroutine") {
+		t.Errorf("logged stack = %q, want a stack trace", stack)
+	}
+}
+
+func TestErrorHandlerPassesThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger, hook := test.NewNullLogger()
+
+	router := gin.New()
+	router.Use(ErrorHandler(logger))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", w.Code)
+	}
+	if len(hook.AllEntries()) != 0 {
+		t.Errorf("logged %d entries for a request that did not panic", len(hook.AllEntries()))
+	}
+}
+
+func TestRequestTimeoutExemptPrefixes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestTimeout(time.Minute, nil, []string{"/debug/pprof"}))
+	hasDeadline := func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, gin.H{"deadline": ok})
+	}
+	router.GET("/debug/pprof/profile", hasDeadline)
+	router.GET("/api/v1/events", hasDeadline)
+
+	tests := []struct {
+		path     string
+		deadline bool
+	}{
+		{"/debug/pprof/profile", false},
+		{"/api/v1/events", true},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		var body struct{ Deadline bool }
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s: decode response: %v", tt.path, err)
+		}
+		if body.Deadline != tt.deadline {
+			t.Errorf("GET %s: request has a deadline = %v, want %v", tt.path, body.Deadline, tt.deadline)
+		}
+	}
+}
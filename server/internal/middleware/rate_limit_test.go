@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitExempt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exempt := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		limited    bool
+	}{
+		{"ipv4 in range", "10.1.2.3:1234", false},
+		{"ipv4 out of range", "192.168.1.1:1234", true},
+		{"ipv4-mapped ipv6 in range", "[::ffff:10.1.2.3]:1234", false},
+		{"ipv6 in range", "[fd12::1]:1234", false},
+		{"ipv6 out of range", "[2001:db8::1]:1234", true},
+		{"unparsable client ip", "not-an-address", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The stub limiter rejects everything it sees, so a 429 means the
+			// request was not exempt
+			calls := 0
+			limiter := func(c *gin.Context) {
+				calls++
+				rateLimitExceeded(c)
+			}
+
+			router := gin.New()
+			router.Use(RateLimitExempt(exempt, limiter))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			want := http.StatusOK
+			if tt.limited {
+				want = http.StatusTooManyRequests
+			}
+			if w.Code != want {
+				t.Errorf("status = %d, want %d", w.Code, want)
+			}
+			if (calls == 1) != tt.limited {
+				t.Errorf("limiter called %d times, limited = %v", calls, tt.limited)
+			}
+		})
+	}
+}
+
+func TestRateLimitExemptWithoutPrefixes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	limiter := func(c *gin.Context) { calls++ }
+	handler := RateLimitExempt(nil, limiter)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	handler(c)
+
+	if calls != 1 {
+		t.Errorf("limiter called %d times, want 1", calls)
+	}
+}
@@ -5,17 +5,144 @@ import (
 )
 
 type Event struct {
-	ID               int       `json:"id" db:"id"`
-	Name             string    `json:"name" db:"name"`
-	Description      string    `json:"description" db:"description"`
-	Venue            string    `json:"venue" db:"venue"`
-	StartTime        time.Time `json:"start_time" db:"start_time"`
-	EndTime          time.Time `json:"end_time" db:"end_time"`
-	TotalTickets     int       `json:"total_tickets" db:"total_tickets"`
-	AvailableTickets int       `json:"available_tickets" db:"available_tickets"`
-	Price            float64   `json:"price" db:"price"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	ID               int         `json:"id" db:"id"`
+	Name             string      `json:"name" db:"name"`
+	Description      string      `json:"description" db:"description"`
+	Venue            string      `json:"venue" db:"venue"`
+	StartTime        time.Time   `json:"start_time" db:"start_time"`
+	EndTime          time.Time   `json:"end_time" db:"end_time"`
+	TotalTickets     int         `json:"total_tickets" db:"total_tickets"`
+	AvailableTickets int         `json:"available_tickets" db:"available_tickets"`
+	Price            Money       `json:"price" db:"price"`
+	Currency         string      `json:"currency" db:"currency"`
+	SeatLabelFormat  string      `json:"seat_label_format,omitempty" db:"seat_label_format"`
+	SeatRows         int         `json:"seat_rows,omitempty" db:"seat_rows"`
+	MaxPerBooking    int         `json:"max_per_booking,omitempty" db:"max_per_booking"`
+	MaxPerUser       int         `json:"max_per_user,omitempty" db:"max_per_user"`
+	ExternalRef      string      `json:"external_ref,omitempty" db:"external_ref"`             // caller's id; creating the same ref twice returns the first event
+	SalesCloseOffset int         `json:"sales_close_offset,omitempty" db:"sales_close_offset"` // seconds before start_time that sales stop
+	SeriesID         int         `json:"series_id,omitempty" db:"series_id"`                   // set on occurrences of a recurring event
+	SeatLockDuration int         `json:"seat_lock_duration,omitempty" db:"seat_lock_duration"` // seconds a seat lock lasts; 0 uses SEAT_LOCK_DURATION
+	SalesOpen        bool        `json:"is_sales_open" db:"is_sales_open"`                     // false while the organizer has paused sales
+	ImageURL         string      `json:"image_url,omitempty" db:"image_url"`                   // poster shown by the UI
+	BannerURL        string      `json:"banner_url,omitempty" db:"banner_url"`
+	Status           EventStatus `json:"status" db:"-"`
+	CreatedAt        time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at" db:"updated_at"`
+	// Explicit seat labels, only accepted on create and never persisted on the event row
+	SeatLabels []string `json:"seat_labels,omitempty" db:"-"`
+}
+
+// StatusAt derives the event status from its schedule at the given time
+func (e *Event) StatusAt(now time.Time) EventStatus {
+	switch {
+	case now.Before(e.StartTime):
+		return EventUpcoming
+	case now.Before(e.EndTime):
+		return EventOngoing
+	default:
+		return EventEnded
+	}
+}
+
+// SalesCloseAt is when bookings stop being accepted: start_time minus the offset
+func (e *Event) SalesCloseAt() time.Time {
+	return e.StartTime.Add(-time.Duration(e.SalesCloseOffset) * time.Second)
+}
+
+// SalesToggleRequest pauses or resumes sales; without Open the current state is flipped
+type SalesToggleRequest struct {
+	Open *bool `json:"open"`
+}
+
+// SalesState reports whether an event is currently selling
+type SalesState struct {
+	EventID   int  `json:"event_id"`
+	SalesOpen bool `json:"is_sales_open"`
+}
+
+// Length limits for an event's free-text fields, counted in characters
+const (
+	MaxEventNameLength        = 200
+	MaxEventVenueLength       = 200
+	MaxEventDescriptionLength = 5000
+	MaxEventURLLength         = 2048 // image_url and banner_url
+)
+
+// LockDuration is how long a seat lock on this event lasts, falling back to
+// defaultDuration when the event has no override
+func (e *Event) LockDuration(defaultDuration time.Duration) time.Duration {
+	if e.SeatLockDuration > 0 {
+		return time.Duration(e.SeatLockDuration) * time.Second
+	}
+	return defaultDuration
+}
+
+// MaxSeriesOccurrences bounds how many events one series request may create
+const MaxSeriesOccurrences = 100
+
+// Recurrence repeats an event daily or weekly, either Count times or until
+// Until (inclusive); exactly one of the two is set
+type Recurrence struct {
+	Frequency string     `json:"frequency" binding:"required,oneof=daily weekly"`
+	Count     int        `json:"count,omitempty" binding:"omitempty,min=1,max=100"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// StartTimes lists the start of every occurrence, beginning with first.
+// Calendar days are added, so local wall-clock times survive DST changes. At
+// most MaxSeriesOccurrences+1 are returned so callers can reject longer series.
+func (r Recurrence) StartTimes(first time.Time) []time.Time {
+	days := 1
+	if r.Frequency == "weekly" {
+		days = 7
+	}
+
+	var starts []time.Time
+	for i := 0; i <= MaxSeriesOccurrences; i++ {
+		start := first.AddDate(0, 0, i*days)
+		if r.Count > 0 && i >= r.Count {
+			break
+		}
+		if r.Until != nil && start.After(*r.Until) {
+			break
+		}
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// SeriesRequest creates one event per recurrence from a base event
+type SeriesRequest struct {
+	Event      Event      `json:"event" binding:"required"`
+	Recurrence Recurrence `json:"recurrence" binding:"required"`
+}
+
+// EventSeries is a set of recurring events created together
+type EventSeries struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Frequency string    `json:"frequency" db:"frequency"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Events    []*Event  `json:"events"`
+}
+
+// EventFilter narrows an event listing; nil bounds are not applied
+type EventFilter struct {
+	CreatedAfter  *time.Time // inclusive
+	CreatedBefore *time.Time // exclusive
+}
+
+// BookingFilter narrows the admin booking list; zero fields match everything
+type BookingFilter struct {
+	Status        BookingStatus
+	CreatedAfter  *time.Time // inclusive
+	CreatedBefore *time.Time // exclusive
+}
+
+// IsZero reports whether the filter matches every event
+func (f EventFilter) IsZero() bool {
+	return f.CreatedAfter == nil && f.CreatedBefore == nil
 }
 
 type Ticket struct {
@@ -27,18 +154,143 @@ type Ticket struct {
 	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
 }
 
+// CheckIn records a sold ticket being admitted at the gate
+type CheckIn struct {
+	TicketID    int       `json:"ticket_id"`
+	EventID     int       `json:"event_id"`
+	SeatNo      string    `json:"seat_no"`
+	BookingRef  string    `json:"booking_ref"`
+	CheckedInAt time.Time `json:"checked_in_at"`
+	CheckedInBy string    `json:"checked_in_by"`
+}
+
+// CheckInRequest identifies a ticket by its booking reference and seat, as
+// printed on the ticket
+type CheckInRequest struct {
+	BookingRef string `json:"booking_ref" binding:"required"`
+	SeatNo     string `json:"seat_no" binding:"required"`
+}
+
 type Booking struct {
 	ID          int           `json:"id" db:"id"`
-	UserID      int           `json:"user_id" db:"user_id"`
+	UserID      int           `json:"user_id,omitempty" db:"user_id"` // 0 for guest bookings
 	EventID     int           `json:"event_id" db:"event_id"`
 	TicketIDs   []int         `json:"ticket_ids" db:"ticket_ids"`
 	Quantity    int           `json:"quantity" db:"quantity"`
-	TotalAmount float64       `json:"total_amount" db:"total_amount"`
+	TotalAmount Money         `json:"total_amount" db:"total_amount"`
+	Currency    string        `json:"currency" db:"currency"`
 	Status      BookingStatus `json:"status" db:"status"`
 	BookingRef  string        `json:"booking_ref" db:"booking_ref"`
 	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
 	ExpiresAt   time.Time     `json:"expires_at" db:"expires_at"`
+	// TotalAmount is Subtotal less DiscountAmount, plus Fees and Tax
+	Subtotal Money `json:"subtotal" db:"subtotal"`
+	Fees     Money `json:"fees" db:"fees_amount"`
+	Tax      Money `json:"tax" db:"tax_amount"`
+	// CouponCode and DiscountAmount record a promo code applied to Subtotal
+	CouponCode     string `json:"coupon_code,omitempty" db:"coupon_code"`
+	DiscountAmount Money  `json:"discount_amount,omitempty" db:"discount_amount"`
+	PaymentRef     string `json:"payment_ref,omitempty" db:"payment_ref"`
+	// Guest holds the buyer's contact details when the booking has no user
+	Guest *GuestContact `json:"guest,omitempty" db:"-"`
+	// Event is only filled in when the caller asks for ?expand=event
+	Event *EventSummary `json:"event,omitempty" db:"-"`
+	// PaymentRequired is false for bookings that were confirmed on creation
+	// (free events); a paid booking a coupon covers in full still needs confirming
+	PaymentRequired bool `json:"payment_required" db:"-"`
+	// SecondsRemaining counts down to ExpiresAt on the server's clock; only set while pending
+	SecondsRemaining *int64 `json:"seconds_remaining,omitempty" db:"-"`
+}
+
+// SetSecondsRemaining fills SecondsRemaining for pending bookings, clamped at 0
+func (b *Booking) SetSecondsRemaining(now time.Time) {
+	if b.Status != BookingPending {
+		b.SecondsRemaining = nil
+		return
+	}
+
+	remaining := int64(b.ExpiresAt.Sub(now).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+	b.SecondsRemaining = &remaining
+}
+
+// AvailabilityCheck is a read-only answer to "would a booking of this size fit?"
+type AvailabilityCheck struct {
+	EventID        int    `json:"event_id"`
+	Quantity       int    `json:"quantity"`
+	AvailableCount int    `json:"available_count"`
+	Available      bool   `json:"available"`
+	EstimatedTotal Money  `json:"estimated_total"`
+	Currency       string `json:"currency"`
+}
+
+// AvailableCount is the number of available seats for an event. It is counted
+// from the tickets unless Fast is set, in which case it was derived from the
+// event's available_tickets counter.
+type AvailableCount struct {
+	EventID   int       `json:"event_id"`
+	Available int       `json:"available"`
+	Fast      bool      `json:"fast,omitempty"`
+	CountedAt time.Time `json:"counted_at"`
+}
+
+// SeatSuggestion is a set of available seats proposed for a group booking
+type SeatSuggestion struct {
+	EventID  int      `json:"event_id"`
+	Quantity int      `json:"quantity"`
+	Seats    []string `json:"seats"`
+	Adjacent bool     `json:"adjacent"` // false when no run of adjacent seats was free
+}
+
+// SeatMap is a ready-to-render layout of an event's seats
+type SeatMap struct {
+	EventID  int              `json:"event_id"`
+	Sections []SeatMapSection `json:"sections"`
+	Counts   SeatCounts       `json:"counts"`
+}
+
+type SeatMapSection struct {
+	Name   string       `json:"name"`
+	Rows   []SeatMapRow `json:"rows"`
+	Counts SeatCounts   `json:"counts"`
+}
+
+type SeatMapRow struct {
+	Name  string        `json:"name"`
+	Seats []SeatMapSeat `json:"seats"`
+}
+
+type SeatMapSeat struct {
+	TicketID int          `json:"ticket_id"`
+	SeatNo   string       `json:"seat_no"`
+	Number   int          `json:"number"`
+	Status   TicketStatus `json:"status"`
+}
+
+type SeatCounts struct {
+	Total     int `json:"total"`
+	Available int `json:"available"`
+	Locked    int `json:"locked"`
+	Reserved  int `json:"reserved"`
+	Sold      int `json:"sold"`
+}
+
+// Add counts one seat with the given status
+func (s *SeatCounts) Add(status TicketStatus) {
+	s.Total++
+	switch status {
+	case TicketAvailable:
+		s.Available++
+	case TicketLocked:
+		s.Locked++
+	case TicketReserved:
+		s.Reserved++
+	case TicketSold:
+		s.Sold++
+	}
 }
 
 type User struct {
@@ -50,26 +302,285 @@ type User struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// MaxTicketsPerBooking is the global safety bound on a single booking; it must
+// match the max in BookingRequest's binding tag. Events may set a lower cap.
+const MaxTicketsPerBooking = 10
+
 type BookingRequest struct {
-	UserID   int `json:"user_id" binding:"required"`
-	EventID  int `json:"event_id" binding:"required"`
+	// Exactly one of UserID and Guest identifies the buyer
+	UserID   int           `json:"user_id,omitempty" binding:"omitempty,min=1"`
+	Guest    *GuestContact `json:"guest,omitempty"`
+	EventID  int           `json:"event_id" binding:"required"`
+	Quantity int           `json:"quantity" binding:"required,min=1,max=10"`
+	// HoldID books exactly the seats of a previously created hold
+	HoldID string `json:"hold_id,omitempty"`
+	// Mode selects how seats are chosen; empty means BookingModeWithLock
+	Mode BookingMode `json:"mode,omitempty" binding:"omitempty,oneof=with_lock auto"`
+	// CouponCode applies a promo code to the total; codes are matched upper-cased
+	CouponCode string `json:"coupon_code,omitempty" binding:"omitempty,max=64"`
+	// SessionID scopes the booking to seats locked by this session; set from X-Session-ID
+	SessionID string `json:"-"`
+}
+
+// EventSummary is the part of an event shown alongside a booking
+type EventSummary struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Venue     string    `json:"venue"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// UserEvent is an event a user has live bookings for, with what they booked
+type UserEvent struct {
+	Event    *Event              `json:"event"`
+	Bookings UserBookingsSummary `json:"bookings"`
+}
+
+// UserBookingsSummary totals a user's pending and confirmed bookings for one event
+type UserBookingsSummary struct {
+	Count        int       `json:"count"`
+	Tickets      int       `json:"tickets"`
+	Confirmed    int       `json:"confirmed"` // bookings already paid for; the rest are pending
+	TotalAmount  Money     `json:"total_amount"`
+	Currency     string    `json:"currency"`
+	BookingIDs   []int     `json:"booking_ids"`
+	LastBookedAt time.Time `json:"last_booked_at"`
+}
+
+// EventTimeframe selects events by whether they are over; ongoing events count as upcoming
+type EventTimeframe string
+
+const (
+	TimeframeUpcoming EventTimeframe = "upcoming"
+	TimeframePast     EventTimeframe = "past"
+)
+
+// Valid reports whether t is a known timeframe
+func (t EventTimeframe) Valid() bool {
+	return t == TimeframeUpcoming || t == TimeframePast
+}
+
+// MaxBookingStatusBatch caps how many bookings one status lookup may ask about
+const MaxBookingStatusBatch = 100
+
+// BookingStatusRequest asks for the status of several bookings by id and/or
+// booking reference
+type BookingStatusRequest struct {
+	IDs  []int    `json:"ids,omitempty" binding:"omitempty,max=100,dive,min=1"`
+	Refs []string `json:"refs,omitempty" binding:"omitempty,max=100,dive,required,max=50"`
+}
+
+// BookingStatusEntry is one booking's status in a batch lookup
+type BookingStatusEntry struct {
+	ID               int           `json:"id"`
+	BookingRef       string        `json:"booking_ref"`
+	Status           BookingStatus `json:"status"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	SecondsRemaining *int64        `json:"seconds_remaining,omitempty"`
+}
+
+// GuestContact identifies a buyer who booked without a user account
+type GuestContact struct {
+	Name  string `json:"name" binding:"required,max=255"`
+	Email string `json:"email" binding:"required,email,max=255"`
+	Phone string `json:"phone,omitempty" binding:"omitempty,max=20"`
+}
+
+// Coupon is a promo code's discount: either PercentOff or AmountOff (in Currency) is set
+type Coupon struct {
+	Code       string `json:"code" db:"code"`
+	PercentOff int    `json:"percent_off,omitempty" db:"percent_off"`
+	AmountOff  Money  `json:"amount_off,omitempty" db:"amount_off"`
+	Currency   string `json:"currency,omitempty" db:"currency"`
+}
+
+// Discount is how much the coupon takes off total, never more than total itself.
+// A fixed amount only makes sense for totals in the coupon's Currency.
+func (c *Coupon) Discount(total Money) Money {
+	discount := c.AmountOff
+	if c.PercentOff > 0 {
+		discount = total.Percent(c.PercentOff)
+	}
+	if discount > total {
+		return total
+	}
+	return discount
+}
+
+// PriceBreakdown itemises what a booking costs
+type PriceBreakdown struct {
+	Subtotal Money `json:"subtotal"`
+	Discount Money `json:"discount_amount"`
+	Fees     Money `json:"fees"`
+	Tax      Money `json:"tax"`
+	Total    Money `json:"total_amount"`
+}
+
+// QuoteRequest asks what a booking would cost, for either specific seats or a
+// number of seats
+type QuoteRequest struct {
+	SeatNumbers []string `json:"seat_numbers,omitempty" binding:"omitempty,max=10,dive,required"`
+	Quantity    int      `json:"quantity,omitempty" binding:"omitempty,min=1,max=10"`
+	CouponCode  string   `json:"coupon_code,omitempty" binding:"omitempty,max=64"`
+}
+
+// QuoteSeat is one line of a quote for specific seats
+type QuoteSeat struct {
+	SeatNo string `json:"seat_no"`
+	Price  Money  `json:"price"`
+}
+
+// Quote is the price breakdown of a prospective booking. Nothing is locked or
+// stored, so the seats may be gone by the time the booking is made.
+type Quote struct {
+	EventID    int         `json:"event_id"`
+	Quantity   int         `json:"quantity"`
+	UnitPrice  Money       `json:"unit_price"`
+	Seats      []QuoteSeat `json:"seats,omitempty"`
+	CouponCode string      `json:"coupon_code,omitempty"`
+	PriceBreakdown
+	Currency string `json:"currency"`
+}
+
+// BookingModification sets a new seat count on a pending booking
+type BookingModification struct {
 	Quantity int `json:"quantity" binding:"required,min=1,max=10"`
 }
 
+// SeatLock is a locked seat as shown to operators. LockedUntil is when cleanup
+// may release it: the hold's expiry for held seats, otherwise the last lock
+// refresh plus the event's seat lock duration.
+type SeatLock struct {
+	TicketID    int        `json:"ticket_id"`
+	SeatNo      string     `json:"seat_no"`
+	SessionID   string     `json:"session_id"`
+	HoldID      string     `json:"hold_id,omitempty"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LockedUntil time.Time  `json:"locked_until"`
+	Overdue     bool       `json:"overdue"` // past LockedUntil but not yet released
+}
+
+// HeldSeat is one seat a session currently has locked
+type HeldSeat struct {
+	TicketID         int       `json:"ticket_id"`
+	SeatNo           string    `json:"seat_no"`
+	HoldID           string    `json:"hold_id,omitempty"`
+	LockedUntil      time.Time `json:"locked_until"`
+	SecondsRemaining int       `json:"seconds_remaining"`
+}
+
+// SessionHolds lists everything a session holds for an event, as shown in a
+// cart. TotalPrice is what booking the seats now would cost before fees.
+type SessionHolds struct {
+	EventID    int         `json:"event_id"`
+	Seats      []*HeldSeat `json:"seats"`
+	TotalPrice Money       `json:"total_price"`
+	Currency   string      `json:"currency"`
+}
+
+// LockClearRequest selects which locked seats an operator releases; no seat
+// numbers means every locked seat of the event
+type LockClearRequest struct {
+	SeatNumbers []string `json:"seat_numbers,omitempty" binding:"omitempty,max=500"`
+	Reason      string   `json:"reason,omitempty" binding:"omitempty,max=255"`
+}
+
+// LockClearSummary reports what clearing an event's locks released
+type LockClearSummary struct {
+	EventID      int      `json:"event_id"`
+	SeatsCleared int      `json:"seats_cleared"`
+	SeatNumbers  []string `json:"seat_numbers"`
+	HoldsExpired int      `json:"holds_expired"`
+}
+
+// TicketRebuild reports the tickets a repair recreated for an event
+type TicketRebuild struct {
+	EventID          int      `json:"event_id"`
+	TicketsAdded     int      `json:"tickets_added"`
+	SeatNumbers      []string `json:"seat_numbers"`
+	AvailableTickets int      `json:"available_tickets"`
+}
+
+// BookingConfirmation is the optional body of a confirm request. PaymentRef
+// records the payment that paid for the booking; it is empty for free or
+// manually settled bookings.
+type BookingConfirmation struct {
+	PaymentRef string `json:"payment_ref,omitempty" binding:"omitempty,max=255"`
+}
+
+// BookingMode controls where BookTickets takes its seats from
+type BookingMode string
+
+const (
+	// BookingModeWithLock books seats the user locked beforehand
+	BookingModeWithLock BookingMode = "with_lock"
+	// BookingModeAuto picks any available seats in the booking transaction,
+	// skipping rows other transactions are working on
+	BookingModeAuto BookingMode = "auto"
+)
+
+type Hold struct {
+	ID          string     `json:"hold_id" db:"id"`
+	EventID     int        `json:"event_id" db:"event_id"`
+	SessionID   string     `json:"session_id" db:"session_id"`
+	TicketIDs   []int      `json:"ticket_ids"`
+	SeatNumbers []string   `json:"seat_numbers"`
+	Status      HoldStatus `json:"status" db:"status"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type HoldRequest struct {
+	Seats []string `json:"seats" binding:"required,min=1,max=10,dive,required"`
+}
+
+// AvailabilityAdjustment is an admin override of an event's available_tickets
+type AvailabilityAdjustment struct {
+	Delta  int    `json:"delta" binding:"required"` // signed; zero is rejected
+	Reason string `json:"reason"`
+}
+
+// BulkCancelSummary reports what cancelling a user's pending bookings did
+type BulkCancelSummary struct {
+	UserID          int   `json:"user_id"`
+	Cancelled       int   `json:"cancelled"`
+	BookingIDs      []int `json:"booking_ids"`
+	TicketsReleased int   `json:"tickets_released"`
+}
+
 type BookingResponse struct {
 	Booking *Booking `json:"booking"`
 	Message string   `json:"message"`
 }
 
 // Enums
+type EventStatus string
+
+const (
+	EventUpcoming EventStatus = "upcoming"
+	EventOngoing  EventStatus = "ongoing"
+	EventEnded    EventStatus = "ended"
+)
+
 type TicketStatus string
 
 const (
 	TicketAvailable TicketStatus = "available"
 	TicketReserved  TicketStatus = "reserved"
 	TicketSold      TicketStatus = "sold"
+	TicketLocked    TicketStatus = "locked"
 )
 
+// Valid reports whether s is one of the known ticket states
+func (s TicketStatus) Valid() bool {
+	switch s {
+	case TicketAvailable, TicketReserved, TicketSold, TicketLocked:
+		return true
+	}
+	return false
+}
+
 type BookingStatus string
 
 const (
@@ -79,16 +590,72 @@ const (
 	BookingExpired   BookingStatus = "expired"
 )
 
+// Valid reports whether s is one of the known booking states
+func (s BookingStatus) Valid() bool {
+	switch s {
+	case BookingPending, BookingConfirmed, BookingCancelled, BookingExpired:
+		return true
+	}
+	return false
+}
+
+// bookingTransitions lists the statuses a booking may move to from each
+// status. Pending to pending is a modification of a pending booking; cancelled
+// and expired are final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingPending:   {BookingPending, BookingConfirmed, BookingCancelled, BookingExpired},
+	BookingConfirmed: {BookingCancelled},
+}
+
+// ValidTransition reports whether a booking in status from may move to status to
+func ValidTransition(from, to BookingStatus) bool {
+	for _, allowed := range bookingTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+type HoldStatus string
+
+const (
+	HoldActive    HoldStatus = "active"
+	HoldConverted HoldStatus = "converted"
+	HoldExpired   HoldStatus = "expired"
+)
+
 // Response types
 type APIResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"` // Machine-readable error code
 	Message string      `json:"message,omitempty"`
+	Meta    *PageInfo   `json:"meta,omitempty"` // Set by paged list endpoints
+}
+
+// PageInfo describes which slice of a paged list Data holds
+type PageInfo struct {
+	Page  int `json:"page,omitempty"`
+	Limit int `json:"limit"`
+	Total int `json:"total"` // Matching items across all pages
+	// Truncated and NextCursor are set by cursor-paged lists when more items
+	// follow; pass NextCursor back as ?after= to fetch them
+	Truncated  bool   `json:"truncated,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Version   string    `json:"version"`
+	Status    string                 `json:"status"`
+	Timestamp time.Time              `json:"timestamp"`
+	Version   string                 `json:"version"`
+	Checks    map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// HealthCheck is the outcome of a single dependency check
+type HealthCheck struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestValidTransition(t *testing.T) {
+	const unknown BookingStatus = "refunded"
+
+	// Columns of each row, in order
+	targets := []BookingStatus{BookingPending, BookingConfirmed, BookingCancelled, BookingExpired, unknown, ""}
+
+	tests := []struct {
+		from BookingStatus
+		to   []bool
+	}{
+		{BookingPending, []bool{true, true, true, true, false, false}},
+		{BookingConfirmed, []bool{false, false, true, false, false, false}},
+		{BookingCancelled, []bool{false, false, false, false, false, false}},
+		{BookingExpired, []bool{false, false, false, false, false, false}},
+		{unknown, []bool{false, false, false, false, false, false}},
+		{"", []bool{false, false, false, false, false, false}},
+	}
+
+	for _, tt := range tests {
+		for i, to := range targets {
+			if got := ValidTransition(tt.from, to); got != tt.to[i] {
+				t.Errorf("ValidTransition(%q, %q) = %v, want %v", tt.from, to, got, tt.to[i])
+			}
+		}
+	}
+}
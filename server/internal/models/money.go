@@ -0,0 +1,252 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for events created without an explicit currency
+// when DEFAULT_CURRENCY is not configured
+const DefaultCurrency = "USD"
+
+// currencyCodes are the active ISO 4217 currency codes, excluding precious
+// metals and testing codes
+var currencyCodes = makeCurrencySet(`
+AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV
+BRL BSD BTN BWP BYN BZD CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUP CVE CZK
+DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL
+HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT
+LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR
+MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF
+SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP
+TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF XCD XCG
+XOF XPF YER ZAR ZMW ZWG`)
+
+func makeCurrencySet(codes string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, code := range strings.Fields(codes) {
+		set[code] = struct{}{}
+	}
+	return set
+}
+
+// IsCurrencyCode reports whether code is an active ISO 4217 alphabetic code
+func IsCurrencyCode(code string) bool {
+	_, ok := currencyCodes[code]
+	return ok
+}
+
+// Money is an amount in minor units (cents), matching the DECIMAL(10,2)
+// columns. It is read and written as exact decimal text both in SQL and in
+// JSON, so no value ever passes through float64.
+type Money int64
+
+// ParseMoney parses a decimal string with at most two fractional digits
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount: empty")
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("invalid amount %q: at most 2 decimal places allowed", s)
+	}
+
+	var units int64
+	if whole != "" {
+		w, err := strconv.ParseUint(whole, 10, 63)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		units = int64(w) * 100
+	}
+	if frac != "" {
+		f, err := strconv.ParseUint(frac+strings.Repeat("0", 2-len(frac)), 10, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		units += int64(f)
+	}
+
+	if negative {
+		units = -units
+	}
+	return Money(units), nil
+}
+
+// String formats the amount as a decimal with two fractional digits
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/100, units%100)
+}
+
+// Percent returns pct percent of the amount, rounded down to a whole minor unit
+func (m Money) Percent(pct int) Money {
+	return m * Money(pct) / 100
+}
+
+// Mul multiplies the amount by a quantity using integer math
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// MarshalJSON writes the amount as a JSON number with exactly two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts either a JSON number or a decimal string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" {
+		return nil
+	}
+
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return m.scanText(string(v))
+	case string:
+		return m.scanText(v)
+	case int64:
+		*m = Money(v * 100)
+		return nil
+	case nil:
+		*m = 0
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+}
+
+func (m *Money) scanText(text string) error {
+	// NUMERIC columns with a larger scale can return trailing zeros, e.g. "12.5000"
+	if whole, frac, ok := strings.Cut(text, "."); ok && len(frac) > 2 {
+		if strings.TrimRight(frac[2:], "0") != "" {
+			return fmt.Errorf("cannot scan %q into Money without losing precision", text)
+		}
+		text = whole + "." + frac[:2]
+	}
+
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, sending the amount as exact decimal text
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Rate is a percentage held in parts per million (1% is 10000), so rates such
+// as 8.875% stay exact
+type Rate int64
+
+const ratePerPercent = 10000
+
+// MaxRate is 100%
+const MaxRate Rate = 100 * ratePerPercent
+
+// ParseRate parses a percentage such as "8.875" or "8.875%" with at most four
+// decimal places
+func ParseRate(s string) (Rate, error) {
+	text := strings.TrimSuffix(strings.TrimSpace(s), "%")
+	whole, frac, _ := strings.Cut(text, ".")
+	if whole == "" || len(frac) > 4 || strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+
+	w, err := strconv.ParseUint(whole, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	rate := Rate(w) * ratePerPercent
+	if frac != "" {
+		f, err := strconv.ParseUint(frac+strings.Repeat("0", 4-len(frac)), 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid rate %q", s)
+		}
+		rate += Rate(f)
+	}
+	return rate, nil
+}
+
+// String formats the rate as a percentage, e.g. "8.875%"
+func (r Rate) String() string {
+	frac := strings.TrimRight(fmt.Sprintf("%04d", r%ratePerPercent), "0")
+	if frac == "" {
+		return fmt.Sprintf("%d%%", r/ratePerPercent)
+	}
+	return fmt.Sprintf("%d.%s%%", r/ratePerPercent, frac)
+}
+
+// ApplyRate returns the rate's share of a non-negative amount, rounded half up
+// to a whole minor unit
+func (m Money) ApplyRate(rate Rate) Money {
+	const scale = 100 * ratePerPercent
+	return (m*Money(rate) + scale/2) / scale
+}
+
+// ServiceFee is charged on top of a booking's tickets: either a flat amount
+// per ticket, in the event's currency, or a rate of the ticket amount
+type ServiceFee struct {
+	PerTicket Money
+	Rate      Rate
+}
+
+// ParseServiceFee parses "1.50" as a flat fee per ticket and "5%" as a rate;
+// an empty string is no fee
+func ParseServiceFee(s string) (ServiceFee, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return ServiceFee{}, nil
+	case strings.HasSuffix(s, "%"):
+		rate, err := ParseRate(s)
+		if err != nil {
+			return ServiceFee{}, err
+		}
+		return ServiceFee{Rate: rate}, nil
+	default:
+		amount, err := ParseMoney(s)
+		if err != nil {
+			return ServiceFee{}, err
+		}
+		if amount < 0 {
+			return ServiceFee{}, fmt.Errorf("invalid amount %q: cannot be negative", s)
+		}
+		return ServiceFee{PerTicket: amount}, nil
+	}
+}
+
+// For is the fee on quantity tickets costing amount in total
+func (f ServiceFee) For(amount Money, quantity int) Money {
+	if f.Rate > 0 {
+		return amount.ApplyRate(f.Rate)
+	}
+	return f.PerTicket.Mul(quantity)
+}
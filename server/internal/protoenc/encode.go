@@ -0,0 +1,180 @@
+// Package protoenc encodes API responses in the Protocol Buffers wire format
+// described by proto/ticket_booking.proto. The encoders are written by hand
+// against protowire rather than generated, so the models stay the single
+// source of truth; field numbers here must match the .proto file.
+package protoenc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// MarshalResponse encodes a ticketbooking.v1.Response. It reports false when
+// the response's data has no protobuf form, so the caller can fall back to JSON.
+func MarshalResponse(response *models.APIResponse) ([]byte, bool) {
+	var b []byte
+	b = appendBool(b, 1, response.Success)
+	b = appendString(b, 2, response.Error)
+	b = appendString(b, 3, response.Code)
+	b = appendString(b, 4, response.Message)
+	if response.Meta != nil {
+		b = appendMessage(b, 5, pageInfo(response.Meta))
+	}
+
+	switch data := response.Data.(type) {
+	case nil:
+	case *models.Event:
+		b = appendMessage(b, 10, event(data))
+	case []*models.Event:
+		var list []byte
+		for _, e := range data {
+			list = appendMessage(list, 1, event(e))
+		}
+		b = appendMessage(b, 11, list)
+	case []*models.Ticket:
+		var list []byte
+		for _, t := range data {
+			list = appendMessage(list, 1, ticket(t))
+		}
+		b = appendMessage(b, 12, list)
+	case *models.SeatMap:
+		b = appendMessage(b, 13, seatMap(data))
+	default:
+		return nil, false
+	}
+
+	return b, true
+}
+
+func pageInfo(p *models.PageInfo) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(p.Page))
+	b = appendInt(b, 2, int64(p.Limit))
+	b = appendInt(b, 3, int64(p.Total))
+	b = appendBool(b, 4, p.Truncated)
+	b = appendString(b, 5, p.NextCursor)
+	return b
+}
+
+func event(e *models.Event) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(e.ID))
+	b = appendString(b, 2, e.Name)
+	b = appendString(b, 3, e.Description)
+	b = appendString(b, 4, e.Venue)
+	b = appendTime(b, 5, e.StartTime)
+	b = appendTime(b, 6, e.EndTime)
+	b = appendInt(b, 7, int64(e.TotalTickets))
+	b = appendInt(b, 8, int64(e.AvailableTickets))
+	b = appendInt(b, 9, int64(e.Price))
+	b = appendString(b, 10, e.Currency)
+	b = appendString(b, 11, e.SeatLabelFormat)
+	b = appendInt(b, 12, int64(e.SeatRows))
+	b = appendInt(b, 13, int64(e.MaxPerBooking))
+	b = appendInt(b, 14, int64(e.MaxPerUser))
+	b = appendString(b, 15, e.ExternalRef)
+	b = appendInt(b, 16, int64(e.SalesCloseOffset))
+	b = appendInt(b, 17, int64(e.SeriesID))
+	b = appendInt(b, 18, int64(e.SeatLockDuration))
+	b = appendBool(b, 19, e.SalesOpen)
+	b = appendString(b, 20, string(e.Status))
+	b = appendTime(b, 21, e.CreatedAt)
+	b = appendTime(b, 22, e.UpdatedAt)
+	b = appendString(b, 23, e.ImageURL)
+	b = appendString(b, 24, e.BannerURL)
+	return b
+}
+
+func ticket(t *models.Ticket) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(t.ID))
+	b = appendInt(b, 2, int64(t.EventID))
+	b = appendString(b, 3, t.SeatNo)
+	b = appendString(b, 4, string(t.Status))
+	b = appendTime(b, 5, t.CreatedAt)
+	b = appendTime(b, 6, t.UpdatedAt)
+	return b
+}
+
+func seatMap(m *models.SeatMap) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(m.EventID))
+	for _, section := range m.Sections {
+		var s []byte
+		s = appendString(s, 1, section.Name)
+		for _, row := range section.Rows {
+			var r []byte
+			r = appendString(r, 1, row.Name)
+			for _, seat := range row.Seats {
+				var st []byte
+				st = appendInt(st, 1, int64(seat.TicketID))
+				st = appendString(st, 2, seat.SeatNo)
+				st = appendInt(st, 3, int64(seat.Number))
+				st = appendString(st, 4, string(seat.Status))
+				r = appendMessage(r, 2, st)
+			}
+			s = appendMessage(s, 2, r)
+		}
+		s = appendMessage(s, 3, seatCounts(section.Counts))
+		b = appendMessage(b, 2, s)
+	}
+	b = appendMessage(b, 3, seatCounts(m.Counts))
+	return b
+}
+
+func seatCounts(c models.SeatCounts) []byte {
+	var b []byte
+	b = appendInt(b, 1, int64(c.Total))
+	b = appendInt(b, 2, int64(c.Available))
+	b = appendInt(b, 3, int64(c.Locked))
+	b = appendInt(b, 4, int64(c.Reserved))
+	b = appendInt(b, 5, int64(c.Sold))
+	return b
+}
+
+// Scalars equal to their zero value are omitted, as proto3 encoders do
+
+func appendInt(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, 1)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// appendMessage writes an embedded message; it is written even when empty so
+// that set-but-empty fields (e.g. an empty event list) stay distinguishable
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// appendTime writes a google.protobuf.Timestamp
+func appendTime(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	var ts []byte
+	ts = appendInt(ts, 1, t.Unix())
+	ts = appendInt(ts, 2, int64(t.Nanosecond()))
+	return appendMessage(b, num, ts)
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
 	"fmt"
-	"strconv"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -12,20 +15,28 @@ import (
 
 	"github.com/milinddethe15/ticket-booking/internal/config"
 	"github.com/milinddethe15/ticket-booking/internal/db"
+	"github.com/milinddethe15/ticket-booking/internal/expiry"
 	"github.com/milinddethe15/ticket-booking/internal/models"
 )
 
 type BookingRepository struct {
-	db     *db.DB
-	logger *logrus.Logger
-	config *config.Config
+	db         *db.DB
+	lockExpiry *expiry.Scheduler
+	payments   PaymentVerifier
+	logger     *logrus.Logger
+	config     *config.Config
 }
 
-func NewBookingRepository(database *db.DB, logger *logrus.Logger, cfg *config.Config) *BookingRepository {
+// NewBookingRepository creates a booking repository; lockExpiry may be nil, and
+// so may payments, in which case bookings can only be confirmed without a
+// payment reference
+func NewBookingRepository(database *db.DB, lockExpiry *expiry.Scheduler, payments PaymentVerifier, logger *logrus.Logger, cfg *config.Config) *BookingRepository {
 	return &BookingRepository{
-		db:     database,
-		logger: logger,
-		config: cfg,
+		db:         database,
+		lockExpiry: lockExpiry,
+		payments:   payments,
+		logger:     logger,
+		config:     cfg,
 	}
 }
 
@@ -33,22 +44,34 @@ func NewBookingRepository(database *db.DB, logger *logrus.Logger, cfg *config.Co
 func (r *BookingRepository) BookTickets(ctx context.Context, request *models.BookingRequest) (*models.Booking, error) {
 	var booking *models.Booking
 
-	err := r.db.WithRetry(ctx, 3, 100*time.Millisecond, func() error {
+	err := r.db.WithRetry(ctx, "book_tickets", r.config.App.MaxRetries, r.config.App.RetryDelay, func() error {
 		return r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
 			var err error
 			booking, err = r.bookTicketsWithLock(ctx, tx, request)
 			return err
 		})
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Booked seats are no longer locks waiting to expire
+	r.lockExpiry.Cancel(booking.TicketIDs...)
 
-	return booking, err
+	booking.SetSecondsRemaining(time.Now())
+	return booking, nil
 }
 
 func (r *BookingRepository) bookTicketsWithLock(ctx context.Context, tx *sql.Tx, request *models.BookingRequest) (*models.Booking, error) {
 	// Step 1: Lock the event row for update (pessimistic lock)
+	// Time checks use the database clock (NOW() is fixed at transaction start),
+	// the same clock every NOW() written below uses, so app/DB clock skew cannot
+	// let a booking through that the stored timestamps would contradict
 	var event models.Event
+	var now time.Time
 	query := `
-		SELECT id, name, available_tickets, price, start_time 
+		SELECT id, name, available_tickets, price, currency, start_time, end_time, 
+			   COALESCE(max_per_booking, 0), COALESCE(max_per_user, 0), sales_close_offset, is_sales_open, NOW() 
 		FROM events 
 		WHERE id = $1 
 		FOR UPDATE`
@@ -58,7 +81,14 @@ func (r *BookingRepository) bookTicketsWithLock(ctx context.Context, tx *sql.Tx,
 		&event.Name,
 		&event.AvailableTickets,
 		&event.Price,
+		&event.Currency,
 		&event.StartTime,
+		&event.EndTime,
+		&event.MaxPerBooking,
+		&event.MaxPerUser,
+		&event.SalesCloseOffset,
+		&event.SalesOpen,
+		&now,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -68,196 +98,556 @@ func (r *BookingRepository) bookTicketsWithLock(ctx context.Context, tx *sql.Tx,
 	}
 
 	// Step 2: Validate event timing
-	if time.Now().After(event.StartTime) {
+	if event.StatusAt(now) == models.EventEnded {
+		return nil, fmt.Errorf("event has already ended")
+	}
+	if now.After(event.StartTime) {
 		return nil, fmt.Errorf("event has already started")
 	}
+	if now.After(event.SalesCloseAt()) {
+		return nil, fmt.Errorf("sales closed for this event at %s", event.SalesCloseAt().Format(time.RFC3339))
+	}
+	if !event.SalesOpen {
+		return nil, &SalesPausedError{EventID: event.ID}
+	}
 
-	// Step 3: Check if user has enough locked seats for this booking
-	// Note: We'll verify exact count after selecting locked tickets
+	// Step 3: Enforce the event's per-booking cap
+	if event.MaxPerBooking > 0 && request.Quantity > event.MaxPerBooking {
+		return nil, fmt.Errorf("quantity exceeds the maximum of %d tickets per booking for this event", event.MaxPerBooking)
+	}
 
-	// Step 4: Lock and select locked tickets (user's selection)
-	ticketQuery := `
-		SELECT id, seat_no 
-		FROM tickets 
-		WHERE event_id = $1 AND status = 'locked' 
-		ORDER BY seat_no 
-		LIMIT $2 
-		FOR UPDATE`
+	// Enforce the event's per-user cap. The event row lock above serialises
+	// concurrent bookings for this event, so the count cannot race.
+	if event.MaxPerUser > 0 {
+		alreadyBooked, err := countBuyerTickets(ctx, tx, request.UserID, guestEmail(request.Guest), request.EventID, 0)
+		if err != nil {
+			return nil, err
+		}
 
-	rows, err := tx.QueryContext(ctx, ticketQuery, request.EventID, request.Quantity)
-	if err != nil {
-		return nil, fmt.Errorf("failed to select tickets: %w", err)
+		if alreadyBooked+request.Quantity > event.MaxPerUser {
+			return nil, fmt.Errorf("booking exceeds the per-user limit of %d tickets for this event (already booked %d)", event.MaxPerUser, alreadyBooked)
+		}
 	}
-	defer rows.Close()
 
+	// Step 4: Lock and select locked tickets (user's selection)
 	var ticketIDs []int
 	var seatNumbers []string
 
-	for rows.Next() {
-		var ticketID int
-		var seatNo string
-		if err := rows.Scan(&ticketID, &seatNo); err != nil {
-			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+	switch {
+	case request.HoldID != "":
+		ticketIDs, seatNumbers, err = r.selectHeldTickets(ctx, tx, request)
+	case request.Mode == models.BookingModeAuto:
+		ticketIDs, seatNumbers, err = r.selectAvailableTickets(ctx, tx, request)
+	default:
+		ticketIDs, seatNumbers, err = r.selectLockedTickets(ctx, tx, request)
+	}
+	if err != nil {
+		if event.AvailableTickets > 0 && isSeatShortage(err) {
+			if invErr := r.checkTicketInventory(ctx, tx, &event); invErr != nil {
+				return nil, invErr
+			}
 		}
-		ticketIDs = append(ticketIDs, ticketID)
-		seatNumbers = append(seatNumbers, seatNo)
+		return nil, err
 	}
 
-	if len(ticketIDs) < request.Quantity {
-		return nil, fmt.Errorf("insufficient locked seats for booking. Found %d locked seats, need %d. Please select seats first", len(ticketIDs), request.Quantity)
+	// Apply the promo code; redeeming counts the use inside this transaction,
+	// so a failed booking does not spend it
+	var coupon *models.Coupon
+	if request.CouponCode != "" {
+		if coupon, err = redeemCoupon(ctx, tx, request.CouponCode, request.EventID); err != nil {
+			return nil, err
+		}
+	}
+
+	price, err := priceBooking(event.Price, request.Quantity, coupon, event.Currency, &r.config.App)
+	if err != nil {
+		return nil, err
+	}
+	totalAmount := price.Total
+
+	// Free events skip the payment step: tickets are sold and the booking is
+	// confirmed in this transaction, with no payment window. This follows the
+	// event's price, not the total: a paid event a coupon brings down to zero
+	// stays pending until it is confirmed, like any other sale of paid seats.
+	// A free event's total is always zero, since free tickets carry no fee.
+	bookingStatus := models.BookingPending
+	ticketStatus := models.TicketReserved
+	expiresAt := now.Add(r.config.App.BookingExpiration)
+	if event.Price == 0 {
+		bookingStatus = models.BookingConfirmed
+		ticketStatus = models.TicketSold
+		expiresAt = now
 	}
 
 	// Step 5: Reserve the tickets
 	updateTicketQuery := `
 		UPDATE tickets 
-		SET status = 'reserved', updated_at = NOW() 
+		SET status = $2, hold_id = NULL, locked_by = NULL, updated_at = NOW() 
 		WHERE id = ANY($1)`
 
-	_, err = tx.ExecContext(ctx, updateTicketQuery, pq.Array(ticketIDs))
+	_, err = tx.ExecContext(ctx, updateTicketQuery, pq.Array(ticketIDs), ticketStatus)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reserve tickets: %w", err)
 	}
 
-	// Step 6: Update event available tickets
+	// Step 6: Update event available tickets. The decrement is conditional so
+	// the counter can never go negative, even if the ticket rows and the
+	// counter have drifted apart; no row updated means not enough tickets.
 	updateEventQuery := `
 		UPDATE events 
 		SET available_tickets = available_tickets - $1, updated_at = NOW() 
-		WHERE id = $2`
+		WHERE id = $2 AND available_tickets >= $1`
 
-	_, err = tx.ExecContext(ctx, updateEventQuery, request.Quantity, request.EventID)
+	result, err := tx.ExecContext(ctx, updateEventQuery, request.Quantity, request.EventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update event: %w", err)
 	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to update event: %w", err)
+	} else if updated == 0 {
+		return nil, fmt.Errorf("insufficient tickets available: requested %d, %d remaining", request.Quantity, event.AvailableTickets)
+	}
 
-	// Step 7: Create booking record
-	totalAmount := event.Price * float64(request.Quantity)
-	bookingRef := r.generateBookingRef()
-	// Use configurable booking expiration duration instead of hardcoded 15 minutes
-	expiresAt := time.Now().Add(r.config.App.BookingExpiration)
-
+	// Step 7: Create booking record. A reference that is already taken
+	// inserts nothing, and the booking is retried with a fresh one.
 	insertBookingQuery := `
-		INSERT INTO bookings (user_id, event_id, ticket_ids, quantity, total_amount, status, booking_ref, expires_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		INSERT INTO bookings (user_id, event_id, ticket_ids, quantity, total_amount, currency, status, booking_ref, expires_at,
+			coupon_code, discount_amount, guest_name, guest_email, guest_phone, subtotal, fees_amount, tax_amount, created_at, updated_at)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''),
+			$15, $16, $17, NOW(), NOW())
+		ON CONFLICT (booking_ref) DO NOTHING
 		RETURNING id, created_at`
 
+	var guest models.GuestContact
+	if request.Guest != nil {
+		guest = *request.Guest
+	}
+
 	var bookingID int
 	var createdAt time.Time
+	var bookingRef string
 
-	err = tx.QueryRowContext(ctx, insertBookingQuery,
-		request.UserID,
-		request.EventID,
-		pq.Array(ticketIDs),
-		request.Quantity,
-		totalAmount,
-		models.BookingPending,
-		bookingRef,
-		expiresAt,
-	).Scan(&bookingID, &createdAt)
+	for attempt := 1; ; attempt++ {
+		if bookingRef, err = r.generateBookingRef(); err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create booking: %w", err)
+		err = tx.QueryRowContext(ctx, insertBookingQuery,
+			request.UserID,
+			request.EventID,
+			pq.Array(ticketIDs),
+			request.Quantity,
+			totalAmount,
+			event.Currency,
+			bookingStatus,
+			bookingRef,
+			expiresAt,
+			request.CouponCode,
+			price.Discount,
+			guest.Name,
+			guest.Email,
+			guest.Phone,
+			price.Subtotal,
+			price.Fees,
+			price.Tax,
+		).Scan(&bookingID, &createdAt)
+
+		if err == nil {
+			break
+		}
+		if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to create booking: %w", err)
+		}
+		if attempt == maxBookingRefAttempts {
+			return nil, fmt.Errorf("failed to create booking: no unused booking reference after %d attempts", attempt)
+		}
+		r.logger.WithField("booking_ref", bookingRef).Warn("Booking reference already taken, generating another")
 	}
 
 	r.logger.WithFields(logrus.Fields{
 		"booking_id":         bookingID,
 		"user_id":            request.UserID,
+		"guest":              request.Guest != nil,
 		"event_id":           request.EventID,
 		"quantity":           request.Quantity,
 		"ticket_ids":         ticketIDs,
 		"seat_numbers":       seatNumbers,
+		"subtotal":           price.Subtotal,
+		"fees":               price.Fees,
+		"tax":                price.Tax,
 		"total_amount":       totalAmount,
+		"coupon_code":        request.CouponCode,
+		"discount_amount":    price.Discount,
+		"hold_id":            request.HoldID,
+		"status":             bookingStatus,
 		"booking_expiration": r.config.App.BookingExpiration,
 	}).Info("Tickets booked successfully")
 
 	return &models.Booking{
-		ID:          bookingID,
-		UserID:      request.UserID,
-		EventID:     request.EventID,
-		TicketIDs:   ticketIDs,
-		Quantity:    request.Quantity,
-		TotalAmount: totalAmount,
-		Status:      models.BookingPending,
-		BookingRef:  bookingRef,
-		CreatedAt:   createdAt,
-		UpdatedAt:   createdAt,
-		ExpiresAt:   expiresAt,
+		ID:              bookingID,
+		UserID:          request.UserID,
+		Guest:           request.Guest,
+		EventID:         request.EventID,
+		TicketIDs:       ticketIDs,
+		Quantity:        request.Quantity,
+		Subtotal:        price.Subtotal,
+		Fees:            price.Fees,
+		Tax:             price.Tax,
+		TotalAmount:     totalAmount,
+		Currency:        event.Currency,
+		Status:          bookingStatus,
+		BookingRef:      bookingRef,
+		CouponCode:      request.CouponCode,
+		DiscountAmount:  price.Discount,
+		PaymentRequired: bookingStatus == models.BookingPending,
+		CreatedAt:       createdAt,
+		UpdatedAt:       createdAt,
+		ExpiresAt:       expiresAt,
 	}, nil
 }
 
-// ConfirmBooking marks a booking as confirmed and tickets as sold
-func (r *BookingRepository) ConfirmBooking(ctx context.Context, bookingID int) error {
+// selectLockedTickets locks the seats the request's session locked for
+// selection outside of any hold. Seats locked by other sessions are never
+// taken, so a request without a session finds none.
+func (r *BookingRepository) selectLockedTickets(ctx context.Context, tx *sql.Tx, request *models.BookingRequest) ([]int, []string, error) {
+	ticketQuery := `
+		SELECT id, seat_no 
+		FROM tickets 
+		WHERE event_id = $1 AND status = 'locked' AND hold_id IS NULL 
+		AND locked_by = $3 
+		ORDER BY seat_no 
+		LIMIT $2 
+		FOR UPDATE`
+
+	ticketIDs, seatNumbers, err := scanTicketSeats(tx.QueryContext(ctx, ticketQuery, request.EventID, request.Quantity, request.SessionID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(ticketIDs) < request.Quantity {
+		availableSeats, err := r.sampleAvailableSeats(ctx, tx, request.EventID, remediationSeatLimit)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, &InsufficientLockedSeatsError{
+			Requested:      request.Quantity,
+			Locked:         len(ticketIDs),
+			AvailableSeats: availableSeats,
+		}
+	}
+
+	return ticketIDs, seatNumbers, nil
+}
+
+// selectAvailableTickets claims any available seats for auto mode bookings.
+// SKIP LOCKED passes over seats another transaction is locking right now, so
+// concurrent bookers end up with disjoint seats instead of waiting on each other.
+func (r *BookingRepository) selectAvailableTickets(ctx context.Context, tx *sql.Tx, request *models.BookingRequest) ([]int, []string, error) {
+	ticketQuery := `
+		SELECT id, seat_no 
+		FROM tickets 
+		WHERE event_id = $1 AND status = 'available' 
+		ORDER BY seat_no 
+		LIMIT $2 
+		FOR UPDATE SKIP LOCKED`
+
+	ticketIDs, seatNumbers, err := scanTicketSeats(tx.QueryContext(ctx, ticketQuery, request.EventID, request.Quantity))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(ticketIDs) < request.Quantity {
+		return nil, nil, fmt.Errorf("insufficient tickets available: requested %d, found %d", request.Quantity, len(ticketIDs))
+	}
+
+	return ticketIDs, seatNumbers, nil
+}
+
+// countBuyerTickets sums the tickets a buyer holds on an event in pending and
+// confirmed bookings, leaving out excludeID. Guests are matched by email.
+func countBuyerTickets(ctx context.Context, tx *sql.Tx, userID int, guestEmail string, eventID int, excludeID int) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(quantity), 0) 
+		FROM bookings 
+		WHERE event_id = $1 AND id <> $2 AND status IN ('pending', 'confirmed') 
+		AND CASE WHEN $3 > 0 THEN user_id = $3 ELSE user_id IS NULL AND lower(guest_email) = lower($4) END`
+
+	var booked int
+	if err := tx.QueryRowContext(ctx, query, eventID, excludeID, userID, guestEmail).Scan(&booked); err != nil {
+		return 0, fmt.Errorf("failed to count user bookings: %w", err)
+	}
+	return booked, nil
+}
+
+// guestEmail is the guest's email, or empty for bookings made by a user
+func guestEmail(guest *models.GuestContact) string {
+	if guest == nil {
+		return ""
+	}
+	return guest.Email
+}
+
+// isSeatShortage reports whether err means the booking couldn't find enough seats
+func isSeatShortage(err error) bool {
+	var lockErr *InsufficientLockedSeatsError
+	return errors.As(err, &lockErr) || strings.HasPrefix(err.Error(), "insufficient tickets available")
+}
+
+// checkTicketInventory tells a genuine shortage apart from corrupt data: it
+// returns an InventoryInconsistencyError when the event's counter claims seats
+// are left but none of its tickets are available or locked
+func (r *BookingRepository) checkTicketInventory(ctx context.Context, tx *sql.Tx, event *models.Event) error {
+	var actual int
+	query := `SELECT COUNT(*) FROM tickets WHERE event_id = $1 AND status IN ('available', 'locked')`
+	if err := tx.QueryRowContext(ctx, query, event.ID).Scan(&actual); err != nil {
+		return fmt.Errorf("failed to check ticket inventory: %w", err)
+	}
+	if actual > 0 {
+		return nil
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"alert":       "ticket_inventory_inconsistent",
+		"event_id":    event.ID,
+		"recorded":    event.AvailableTickets,
+		"actual":      actual,
+		"remediation": fmt.Sprintf("POST /admin/events/%d/reconcile", event.ID),
+	}).Error("Event has available_tickets but no available tickets")
+
+	return &InventoryInconsistencyError{
+		EventID:  event.ID,
+		Recorded: event.AvailableTickets,
+		Actual:   actual,
+	}
+}
+
+// sampleAvailableSeats lists a few seats the user could lock instead
+func (r *BookingRepository) sampleAvailableSeats(ctx context.Context, tx *sql.Tx, eventID int, limit int) ([]string, error) {
+	query := `
+		SELECT seat_no 
+		FROM tickets 
+		WHERE event_id = $1 AND status = 'available' 
+		ORDER BY seat_no 
+		LIMIT $2`
+
+	rows, err := tx.QueryContext(ctx, query, eventID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available seats: %w", err)
+	}
+	defer rows.Close()
+
+	seats := []string{}
+	for rows.Next() {
+		var seatNo string
+		if err := rows.Scan(&seatNo); err != nil {
+			return nil, fmt.Errorf("failed to scan available seat: %w", err)
+		}
+		seats = append(seats, seatNo)
+	}
+
+	return seats, rows.Err()
+}
+
+// selectHeldTickets validates the hold and locks its seats, marking the hold as converted
+func (r *BookingRepository) selectHeldTickets(ctx context.Context, tx *sql.Tx, request *models.BookingRequest) ([]int, []string, error) {
+	var eventID int
+	var status models.HoldStatus
+	var expiresAt, now time.Time
+
+	holdQuery := `
+		SELECT event_id, status, expires_at, NOW() 
+		FROM holds 
+		WHERE id = $1 
+		FOR UPDATE`
+
+	err := tx.QueryRowContext(ctx, holdQuery, request.HoldID).Scan(&eventID, &status, &expiresAt, &now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("hold not found")
+		}
+		return nil, nil, fmt.Errorf("failed to lock hold: %w", err)
+	}
+
+	if eventID != request.EventID {
+		return nil, nil, fmt.Errorf("hold does not belong to this event")
+	}
+	if status != models.HoldActive {
+		return nil, nil, fmt.Errorf("hold is no longer active (current status: %s)", status)
+	}
+	if now.After(expiresAt) {
+		return nil, nil, fmt.Errorf("hold has expired")
+	}
+
+	ticketQuery := `
+		SELECT id, seat_no 
+		FROM tickets 
+		WHERE hold_id = $1 AND status = 'locked' 
+		ORDER BY seat_no 
+		FOR UPDATE`
+
+	ticketIDs, seatNumbers, err := scanTicketSeats(tx.QueryContext(ctx, ticketQuery, request.HoldID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(ticketIDs) == 0 {
+		return nil, nil, fmt.Errorf("hold has no remaining seats")
+	}
+	if len(ticketIDs) != request.Quantity {
+		return nil, nil, fmt.Errorf("hold does not match requested quantity. Hold has %d seats, requested %d", len(ticketIDs), request.Quantity)
+	}
+
+	convertQuery := `UPDATE holds SET status = 'converted', updated_at = NOW() WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, convertQuery, request.HoldID); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert hold: %w", err)
+	}
+
+	return ticketIDs, seatNumbers, nil
+}
+
+func scanTicketSeats(rows *sql.Rows, err error) ([]int, []string, error) {
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to select tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var ticketIDs []int
+	var seatNumbers []string
+
+	for rows.Next() {
+		var ticketID int
+		var seatNo string
+		if err := rows.Scan(&ticketID, &seatNo); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		ticketIDs = append(ticketIDs, ticketID)
+		seatNumbers = append(seatNumbers, seatNo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read tickets: %w", err)
+	}
+
+	return ticketIDs, seatNumbers, nil
+}
+
+// ConfirmBooking marks a booking as confirmed and tickets as sold. A non-empty
+// paymentRef is checked with the PaymentVerifier and recorded on the booking in
+// the same transaction. If it can't be verified, or already confirmed another
+// booking, nothing changes and the booking stays pending.
+func (r *BookingRepository) ConfirmBooking(ctx context.Context, bookingID int, paymentRef string) error {
 	return r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
 		// Get booking details with lock
 		var booking models.Booking
+		var now time.Time
 		query := `
-			SELECT id, ticket_ids, status, expires_at 
+			SELECT id, event_id, ticket_ids, quantity, total_amount, currency, status, booking_ref, expires_at, NOW() 
 			FROM bookings 
 			WHERE id = $1 
 			FOR UPDATE`
 
-		var ticketIDsStr string
+		var ticketIDArray pq.Int64Array
 		err := tx.QueryRowContext(ctx, query, bookingID).Scan(
 			&booking.ID,
-			&ticketIDsStr,
+			&booking.EventID,
+			&ticketIDArray,
+			&booking.Quantity,
+			&booking.TotalAmount,
+			&booking.Currency,
 			&booking.Status,
+			&booking.BookingRef,
 			&booking.ExpiresAt,
+			&now,
 		)
 		if err != nil {
 			return fmt.Errorf("booking not found: %w", err)
 		}
 
 		// Validate booking status and expiry
-		if booking.Status != models.BookingPending {
-			return fmt.Errorf("booking is not in pending status")
+		if !models.ValidTransition(booking.Status, models.BookingConfirmed) {
+			return &InvalidTransitionError{BookingID: bookingID, From: booking.Status, To: models.BookingConfirmed}
 		}
 
-		if time.Now().After(booking.ExpiresAt) {
+		if now.After(booking.ExpiresAt) {
 			return fmt.Errorf("booking has expired")
 		}
 
-		// Parse ticket IDs
-		ticketIDs := parseTicketIDs(ticketIDsStr)
+		ticketIDs := toInts(ticketIDArray)
+		booking.TicketIDs = ticketIDs
+
+		// The payment must check out before anything is sold
+		if paymentRef != "" {
+			if err := r.verifyPayment(ctx, &booking, paymentRef); err != nil {
+				return err
+			}
+		}
 
 		r.logger.WithFields(logrus.Fields{
-			"booking_id":        bookingID,
-			"ticket_ids_string": ticketIDsStr,
-			"parsed_ticket_ids": ticketIDs,
+			"booking_id": bookingID,
+			"ticket_ids": ticketIDs,
 		}).Debug("Confirming booking with ticket IDs")
 
 		// Update tickets to sold
 		updateTicketsQuery := `
 			UPDATE tickets 
 			SET status = 'sold', updated_at = NOW() 
-			WHERE id = ANY($1) AND status = 'reserved'`
+			WHERE id = ANY($1) AND status = 'reserved'
+			RETURNING id`
 
-		result, err := tx.ExecContext(ctx, updateTicketsQuery, pq.Array(ticketIDs))
+		rows, err := tx.QueryContext(ctx, updateTicketsQuery, pq.Array(ticketIDs))
 		if err != nil {
 			return fmt.Errorf("failed to confirm tickets: %w", err)
 		}
+		confirmed := make(map[int]bool, len(ticketIDs))
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to confirm tickets: %w", err)
+			}
+			confirmed[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to confirm tickets: %w", err)
+		}
 
-		rowsAffected, _ := result.RowsAffected()
-		if int(rowsAffected) != len(ticketIDs) {
+		if len(confirmed) != len(ticketIDs) {
+			// Returning the error rolls back the tickets that did move to sold
+			mismatch := &TicketConfirmationError{BookingID: bookingID}
+			for _, id := range ticketIDs {
+				if !confirmed[id] {
+					mismatch.TicketIDs = append(mismatch.TicketIDs, id)
+				}
+			}
 			r.logger.WithFields(logrus.Fields{
 				"booking_id":     bookingID,
 				"ticket_ids":     ticketIDs,
+				"unconfirmed":    mismatch.TicketIDs,
 				"expected_count": len(ticketIDs),
-				"rows_affected":  rowsAffected,
+				"rows_affected":  len(confirmed),
 			}).Error("Mismatch in ticket confirmation count")
-			return fmt.Errorf("some tickets could not be confirmed")
+			return mismatch
 		}
 
 		// Update booking status
 		updateBookingQuery := `
 			UPDATE bookings 
-			SET status = 'confirmed', updated_at = NOW() 
+			SET status = 'confirmed', payment_ref = NULLIF($2, ''), updated_at = NOW() 
 			WHERE id = $1`
 
-		_, err = tx.ExecContext(ctx, updateBookingQuery, bookingID)
+		_, err = tx.ExecContext(ctx, updateBookingQuery, bookingID, paymentRef)
 		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "idx_bookings_payment_ref" {
+				return fmt.Errorf("payment reference %q has already been used to confirm another booking", paymentRef)
+			}
 			return fmt.Errorf("failed to confirm booking: %w", err)
 		}
 
-		r.logger.WithField("booking_id", bookingID).Info("Booking confirmed successfully")
+		r.logger.WithFields(logrus.Fields{
+			"booking_id":  bookingID,
+			"payment_ref": paymentRef,
+		}).Info("Booking confirmed successfully")
 		return nil
 	})
 }
@@ -273,11 +663,11 @@ func (r *BookingRepository) CancelBooking(ctx context.Context, bookingID int) er
 			WHERE id = $1 
 			FOR UPDATE`
 
-		var ticketIDsStr string
+		var ticketIDArray pq.Int64Array
 		err := tx.QueryRowContext(ctx, query, bookingID).Scan(
 			&booking.ID,
 			&booking.EventID,
-			&ticketIDsStr,
+			&ticketIDArray,
 			&booking.Quantity,
 			&booking.Status,
 		)
@@ -285,23 +675,41 @@ func (r *BookingRepository) CancelBooking(ctx context.Context, bookingID int) er
 			return fmt.Errorf("booking not found: %w", err)
 		}
 
-		if booking.Status == models.BookingCancelled {
-			return fmt.Errorf("booking is already cancelled")
+		if !models.ValidTransition(booking.Status, models.BookingCancelled) {
+			return &InvalidTransitionError{BookingID: bookingID, From: booking.Status, To: models.BookingCancelled}
+		}
+
+		// Claim the status transition first. Anything that already moved the
+		// booking out of pending/confirmed has released its seats, so zero
+		// rows means there is nothing left to restore.
+		updateBookingQuery := `
+			UPDATE bookings 
+			SET status = 'cancelled', updated_at = NOW() 
+			WHERE id = $1 AND status IN ('pending', 'confirmed')`
+
+		result, err := tx.ExecContext(ctx, updateBookingQuery, bookingID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel booking: %w", err)
+		}
+		if updated, _ := result.RowsAffected(); updated == 0 {
+			return &InvalidTransitionError{BookingID: bookingID, From: booking.Status, To: models.BookingCancelled}
 		}
 
-		// Parse ticket IDs
-		ticketIDs := parseTicketIDs(ticketIDsStr)
+		ticketIDs := toInts(ticketIDArray)
 
-		// Release tickets back to available
+		// Release only seats this booking still holds, and restore exactly
+		// that many, so availability is never counted back twice. A released
+		// seat loses its check-in so whoever buys it next can be admitted.
 		updateTicketsQuery := `
 			UPDATE tickets 
-			SET status = 'available', updated_at = NOW() 
-			WHERE id = ANY($1)`
+			SET status = 'available', locked_by = NULL, checked_in_at = NULL, checked_in_by = NULL, updated_at = NOW() 
+			WHERE id = ANY($1) AND status IN ('reserved', 'sold')`
 
-		_, err = tx.ExecContext(ctx, updateTicketsQuery, pq.Array(ticketIDs))
+		result, err = tx.ExecContext(ctx, updateTicketsQuery, pq.Array(ticketIDs))
 		if err != nil {
 			return fmt.Errorf("failed to release tickets: %w", err)
 		}
+		released, _ := result.RowsAffected()
 
 		// Update event available tickets
 		updateEventQuery := `
@@ -309,52 +717,497 @@ func (r *BookingRepository) CancelBooking(ctx context.Context, bookingID int) er
 			SET available_tickets = available_tickets + $1, updated_at = NOW() 
 			WHERE id = $2`
 
-		_, err = tx.ExecContext(ctx, updateEventQuery, booking.Quantity, booking.EventID)
+		_, err = tx.ExecContext(ctx, updateEventQuery, released, booking.EventID)
 		if err != nil {
 			return fmt.Errorf("failed to update event: %w", err)
 		}
 
-		// Update booking status
+		r.logger.WithField("booking_id", bookingID).Info("Booking cancelled successfully")
+		return nil
+	})
+}
+
+// ModifyBooking changes the number of seats on a pending booking. Extra seats
+// are taken from the event's available tickets; fewer seats release the most
+// recently added ones. Quantity and total are recomputed in the same transaction.
+// Adding seats fails with a SalesPausedError while the event's sales are paused.
+func (r *BookingRepository) ModifyBooking(ctx context.Context, bookingID int, quantity int) (*models.Booking, error) {
+	var added, released []int
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var booking models.Booking
+		var ticketIDArray pq.Int64Array
+		var now time.Time
+
+		query := `
+			SELECT id, COALESCE(user_id, 0), COALESCE(guest_email, ''), event_id, ticket_ids, quantity, status, expires_at, 
+				COALESCE(coupon_code, ''), NOW() 
+			FROM bookings 
+			WHERE id = $1 
+			FOR UPDATE`
+
+		var buyerEmail string
+		err := tx.QueryRowContext(ctx, query, bookingID).Scan(
+			&booking.ID,
+			&booking.UserID,
+			&buyerEmail,
+			&booking.EventID,
+			&ticketIDArray,
+			&booking.Quantity,
+			&booking.Status,
+			&booking.ExpiresAt,
+			&booking.CouponCode,
+			&now,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("booking not found")
+			}
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
+
+		if !models.ValidTransition(booking.Status, models.BookingPending) {
+			return &InvalidTransitionError{BookingID: bookingID, From: booking.Status, To: models.BookingPending}
+		}
+		if now.After(booking.ExpiresAt) {
+			return fmt.Errorf("booking has expired")
+		}
+
+		ticketIDs := toInts(ticketIDArray)
+		delta := quantity - len(ticketIDs)
+		if delta == 0 {
+			return nil
+		}
+
+		// Lock the event row as BookTickets does, so caps and the counter
+		// cannot race with new bookings for this event
+		var event models.Event
+		eventQuery := `
+			SELECT id, price, currency, start_time, COALESCE(max_per_booking, 0), COALESCE(max_per_user, 0), sales_close_offset, 
+				is_sales_open 
+			FROM events 
+			WHERE id = $1 
+			FOR UPDATE`
+
+		err = tx.QueryRowContext(ctx, eventQuery, booking.EventID).Scan(
+			&event.ID,
+			&event.Price,
+			&event.Currency,
+			&event.StartTime,
+			&event.MaxPerBooking,
+			&event.MaxPerUser,
+			&event.SalesCloseOffset,
+			&event.SalesOpen,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to lock event: %w", err)
+		}
+
+		if now.After(event.StartTime) {
+			return fmt.Errorf("event has already started")
+		}
+		if now.After(event.SalesCloseAt()) {
+			return fmt.Errorf("sales closed for this event at %s", event.SalesCloseAt().Format(time.RFC3339))
+		}
+		if event.MaxPerBooking > 0 && quantity > event.MaxPerBooking {
+			return fmt.Errorf("quantity exceeds the maximum of %d tickets per booking for this event", event.MaxPerBooking)
+		}
+
+		if delta > 0 {
+			// Paused sales stop new seats going out; giving seats back is still allowed
+			if !event.SalesOpen {
+				return &SalesPausedError{EventID: event.ID}
+			}
+			if event.MaxPerUser > 0 {
+				otherBooked, err := countBuyerTickets(ctx, tx, booking.UserID, buyerEmail, booking.EventID, bookingID)
+				if err != nil {
+					return err
+				}
+				if otherBooked+quantity > event.MaxPerUser {
+					return fmt.Errorf("booking exceeds the per-user limit of %d tickets for this event (already booked %d)", event.MaxPerUser, otherBooked)
+				}
+			}
+
+			added, _, err = r.selectAvailableTickets(ctx, tx, &models.BookingRequest{EventID: booking.EventID, Quantity: delta})
+			if err != nil {
+				return err
+			}
+
+			reserveQuery := `
+				UPDATE tickets 
+				SET status = 'reserved', hold_id = NULL, locked_by = NULL, updated_at = NOW() 
+				WHERE id = ANY($1)`
+
+			if _, err := tx.ExecContext(ctx, reserveQuery, pq.Array(added)); err != nil {
+				return fmt.Errorf("failed to reserve tickets: %w", err)
+			}
+
+			updateEventQuery := `
+				UPDATE events 
+				SET available_tickets = available_tickets - $1, updated_at = NOW() 
+				WHERE id = $2 AND available_tickets >= $1`
+
+			result, err := tx.ExecContext(ctx, updateEventQuery, delta, booking.EventID)
+			if err != nil {
+				return fmt.Errorf("failed to update event: %w", err)
+			}
+			if updated, err := result.RowsAffected(); err != nil {
+				return fmt.Errorf("failed to update event: %w", err)
+			} else if updated == 0 {
+				return fmt.Errorf("insufficient tickets available: requested %d more", delta)
+			}
+
+			ticketIDs = append(ticketIDs, added...)
+		} else {
+			released = ticketIDs[quantity:]
+			ticketIDs = ticketIDs[:quantity]
+
+			releaseQuery := `
+				UPDATE tickets 
+				SET status = 'available', locked_by = NULL, updated_at = NOW() 
+				WHERE id = ANY($1) AND status = 'reserved'`
+
+			result, err := tx.ExecContext(ctx, releaseQuery, pq.Array(released))
+			if err != nil {
+				return fmt.Errorf("failed to release tickets: %w", err)
+			}
+			freed, _ := result.RowsAffected()
+
+			updateEventQuery := `
+				UPDATE events 
+				SET available_tickets = available_tickets + $1, updated_at = NOW() 
+				WHERE id = $2`
+
+			if _, err := tx.ExecContext(ctx, updateEventQuery, freed, booking.EventID); err != nil {
+				return fmt.Errorf("failed to update event: %w", err)
+			}
+		}
+
+		// The coupon was already redeemed at booking time; only its terms are reapplied
+		var coupon *models.Coupon
+		if booking.CouponCode != "" {
+			if coupon, err = lookupCoupon(ctx, tx, booking.CouponCode); err != nil {
+				return err
+			}
+		}
+
+		price, err := priceBooking(event.Price, quantity, coupon, event.Currency, &r.config.App)
+		if err != nil {
+			return err
+		}
+
 		updateBookingQuery := `
 			UPDATE bookings 
-			SET status = 'cancelled', updated_at = NOW() 
+			SET ticket_ids = $2, quantity = $3, total_amount = $4, discount_amount = $5,
+				subtotal = $6, fees_amount = $7, tax_amount = $8, updated_at = NOW() 
 			WHERE id = $1`
 
-		_, err = tx.ExecContext(ctx, updateBookingQuery, bookingID)
+		_, err = tx.ExecContext(ctx, updateBookingQuery, bookingID, pq.Array(ticketIDs), quantity,
+			price.Total, price.Discount, price.Subtotal, price.Fees, price.Tax)
 		if err != nil {
-			return fmt.Errorf("failed to cancel booking: %w", err)
+			return fmt.Errorf("failed to update booking: %w", err)
 		}
 
-		r.logger.WithField("booking_id", bookingID).Info("Booking cancelled successfully")
 		return nil
 	})
-}
+	if err != nil {
+		return nil, err
+	}
 
-// GetBooking retrieves booking details
-func (r *BookingRepository) GetBooking(ctx context.Context, bookingID int) (*models.Booking, error) {
-	query := `
-		SELECT id, user_id, event_id, ticket_ids, quantity, total_amount, 
-			   status, booking_ref, created_at, updated_at, expires_at
-		FROM bookings 
-		WHERE id = $1`
+	r.logger.WithFields(logrus.Fields{
+		"booking_id":       bookingID,
+		"quantity":         quantity,
+		"tickets_added":    added,
+		"tickets_released": released,
+	}).Info("Booking modified")
 
+	return r.GetBooking(ctx, bookingID)
+}
+
+// ForceExpireBooking expires a pending booking ahead of its expires_at,
+// releasing its reserved tickets and restoring event availability. actor
+// identifies who requested it and is written to the audit log.
+func (r *BookingRepository) ForceExpireBooking(ctx context.Context, bookingID int, actor string) error {
 	var booking models.Booking
-	var ticketIDsStr string
+	var released int64
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		query := `
+			SELECT id, event_id, ticket_ids, quantity, status 
+			FROM bookings 
+			WHERE id = $1 
+			FOR UPDATE`
+
+		var ticketIDArray pq.Int64Array
+		err := tx.QueryRowContext(ctx, query, bookingID).Scan(
+			&booking.ID,
+			&booking.EventID,
+			&ticketIDArray,
+			&booking.Quantity,
+			&booking.Status,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("booking not found")
+			}
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
+
+		if !models.ValidTransition(booking.Status, models.BookingExpired) {
+			return &InvalidTransitionError{BookingID: bookingID, From: booking.Status, To: models.BookingExpired}
+		}
+
+		// Same precondition as CancelBooking: whoever moves the booking out
+		// of pending first releases its seats
+		updateBookingQuery := `
+			UPDATE bookings 
+			SET status = 'expired', updated_at = NOW() 
+			WHERE id = $1 AND status = 'pending'`
+
+		result, err := tx.ExecContext(ctx, updateBookingQuery, bookingID)
+		if err != nil {
+			return fmt.Errorf("failed to expire booking: %w", err)
+		}
+		if updated, _ := result.RowsAffected(); updated == 0 {
+			return &InvalidTransitionError{BookingID: bookingID, From: booking.Status, To: models.BookingExpired}
+		}
+
+		booking.TicketIDs = toInts(ticketIDArray)
+
+		// Only reserved tickets belong to the pending booking; anything else
+		// has already been released or resold and must not be touched
+		releaseQuery := `
+			UPDATE tickets 
+			SET status = 'available', locked_by = NULL, updated_at = NOW() 
+			WHERE id = ANY($1) AND status = 'reserved'`
+
+		result, err = tx.ExecContext(ctx, releaseQuery, pq.Array(booking.TicketIDs))
+		if err != nil {
+			return fmt.Errorf("failed to release tickets: %w", err)
+		}
+		released, _ = result.RowsAffected()
+
+		updateEventQuery := `
+			UPDATE events 
+			SET available_tickets = available_tickets + $1, updated_at = NOW() 
+			WHERE id = $2`
+
+		if _, err := tx.ExecContext(ctx, updateEventQuery, released, booking.EventID); err != nil {
+			return fmt.Errorf("failed to update event: %w", err)
+		}
+
+		return nil
+	})
 
-	err := r.db.QueryRowContext(ctx, query, bookingID).Scan(
+	if err != nil {
+		return err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"audit":            true,
+		"action":           "booking.force_expire",
+		"actor":            actor,
+		"booking_id":       bookingID,
+		"event_id":         booking.EventID,
+		"ticket_ids":       booking.TicketIDs,
+		"tickets_released": released,
+	}).Warn("Booking force-expired")
+
+	return nil
+}
+
+// CancelPendingBookings cancels every pending booking of a user in one
+// transaction, releasing their reserved tickets and restoring availability per
+// event. Confirmed and already-finished bookings are left alone. actor is
+// written to the audit log.
+func (r *BookingRepository) CancelPendingBookings(ctx context.Context, userID int, actor string) (*models.BulkCancelSummary, error) {
+	summary := &models.BulkCancelSummary{UserID: userID, BookingIDs: []int{}}
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		// Same lock order as CancelBooking: bookings, then tickets, then events
+		query := `
+			SELECT id, ticket_ids 
+			FROM bookings 
+			WHERE user_id = $1 AND status = 'pending' 
+			ORDER BY id 
+			FOR UPDATE`
+
+		rows, err := tx.QueryContext(ctx, query, userID)
+		if err != nil {
+			return fmt.Errorf("failed to lock bookings: %w", err)
+		}
+		defer rows.Close()
+
+		var ticketIDs []int
+		for rows.Next() {
+			var bookingID int
+			var ticketIDArray pq.Int64Array
+			if err := rows.Scan(&bookingID, &ticketIDArray); err != nil {
+				return fmt.Errorf("failed to scan booking: %w", err)
+			}
+			summary.BookingIDs = append(summary.BookingIDs, bookingID)
+			ticketIDs = append(ticketIDs, toInts(ticketIDArray)...)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read bookings: %w", err)
+		}
+		if len(summary.BookingIDs) == 0 {
+			return nil
+		}
+
+		updateBookingsQuery := `
+			UPDATE bookings 
+			SET status = 'cancelled', updated_at = NOW() 
+			WHERE id = ANY($1) AND status = 'pending'`
+
+		result, err := tx.ExecContext(ctx, updateBookingsQuery, pq.Array(summary.BookingIDs))
+		if err != nil {
+			return fmt.Errorf("failed to cancel bookings: %w", err)
+		}
+		cancelled, _ := result.RowsAffected()
+		summary.Cancelled = int(cancelled)
+
+		// Count released seats per event so each counter is restored exactly
+		releaseQuery := `
+			WITH released AS (
+				UPDATE tickets 
+				SET status = 'available', locked_by = NULL, updated_at = NOW() 
+				WHERE id = ANY($1) AND status = 'reserved' 
+				RETURNING event_id
+			)
+			SELECT event_id, COUNT(*) FROM released GROUP BY event_id ORDER BY event_id`
+
+		releasedRows, err := tx.QueryContext(ctx, releaseQuery, pq.Array(ticketIDs))
+		if err != nil {
+			return fmt.Errorf("failed to release tickets: %w", err)
+		}
+		defer releasedRows.Close()
+
+		releasedByEvent := make(map[int]int)
+		var eventIDs []int
+		for releasedRows.Next() {
+			var eventID, count int
+			if err := releasedRows.Scan(&eventID, &count); err != nil {
+				return fmt.Errorf("failed to scan released tickets: %w", err)
+			}
+			releasedByEvent[eventID] = count
+			eventIDs = append(eventIDs, eventID)
+			summary.TicketsReleased += count
+		}
+		if err := releasedRows.Err(); err != nil {
+			return fmt.Errorf("failed to read released tickets: %w", err)
+		}
+
+		// Events are updated in id order so concurrent bulk cancels can't deadlock
+		updateEventQuery := `
+			UPDATE events 
+			SET available_tickets = available_tickets + $1, updated_at = NOW() 
+			WHERE id = $2`
+
+		for _, eventID := range eventIDs {
+			if _, err := tx.ExecContext(ctx, updateEventQuery, releasedByEvent[eventID], eventID); err != nil {
+				return fmt.Errorf("failed to update event %d: %w", eventID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"audit":            true,
+		"action":           "user.cancel_pending_bookings",
+		"actor":            actor,
+		"user_id":          userID,
+		"booking_ids":      summary.BookingIDs,
+		"cancelled":        summary.Cancelled,
+		"tickets_released": summary.TicketsReleased,
+	}).Warn("Pending bookings cancelled for user")
+
+	return summary, nil
+}
+
+// bookingColumns is the select list scanBooking expects, for bookings aliased as b
+const bookingColumns = `b.id, COALESCE(b.user_id, 0), b.event_id, b.ticket_ids, b.quantity, b.total_amount, b.currency, 
+	b.status, b.booking_ref, b.created_at, b.updated_at, b.expires_at,
+	COALESCE(b.coupon_code, ''), b.discount_amount, COALESCE(b.payment_ref, ''),
+	COALESCE(b.guest_name, ''), COALESCE(b.guest_email, ''), COALESCE(b.guest_phone, ''),
+	b.subtotal, b.fees_amount, b.tax_amount`
+
+// scanBooking scans bookingColumns, followed by any extra columns into extra
+func scanBooking(row rowScanner, booking *models.Booking, extra ...interface{}) error {
+	var ticketIDArray pq.Int64Array
+	var guest models.GuestContact
+
+	dest := []interface{}{
 		&booking.ID,
 		&booking.UserID,
 		&booking.EventID,
-		&ticketIDsStr,
+		&ticketIDArray,
 		&booking.Quantity,
 		&booking.TotalAmount,
+		&booking.Currency,
 		&booking.Status,
 		&booking.BookingRef,
 		&booking.CreatedAt,
 		&booking.UpdatedAt,
 		&booking.ExpiresAt,
-	)
+		&booking.CouponCode,
+		&booking.DiscountAmount,
+		&booking.PaymentRef,
+		&guest.Name,
+		&guest.Email,
+		&guest.Phone,
+		&booking.Subtotal,
+		&booking.Fees,
+		&booking.Tax,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
 
+	if guest.Email != "" {
+		booking.Guest = &guest
+	}
+	booking.TicketIDs = toInts(ticketIDArray)
+	booking.PaymentRequired = booking.Status == models.BookingPending
+	booking.SetSecondsRemaining(time.Now())
+	return nil
+}
+
+// GetBooking retrieves booking details
+func (r *BookingRepository) GetBooking(ctx context.Context, bookingID int) (*models.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings b 
+		WHERE b.id = $1`
+
+	var booking models.Booking
+	if err := scanBooking(r.db.QueryRowContext(ctx, query, bookingID), &booking); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("booking not found")
+		}
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetBookingWithEvent retrieves booking details with a summary of the event
+// embedded, joined in the same query
+func (r *BookingRepository) GetBookingWithEvent(ctx context.Context, bookingID int) (*models.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `, 
+			   e.id, e.name, COALESCE(e.venue, ''), e.start_time, e.end_time 
+		FROM bookings b 
+		JOIN events e ON e.id = b.event_id 
+		WHERE b.id = $1`
+
+	var booking models.Booking
+	var event models.EventSummary
+	err := scanBooking(r.db.QueryRowContext(ctx, query, bookingID), &booking,
+		&event.ID, &event.Name, &event.Venue, &event.StartTime, &event.EndTime)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("booking not found")
@@ -362,63 +1215,137 @@ func (r *BookingRepository) GetBooking(ctx context.Context, bookingID int) (*mod
 		return nil, err
 	}
 
-	booking.TicketIDs = parseTicketIDs(ticketIDsStr)
+	booking.Event = &event
 	return &booking, nil
 }
 
-// Helper functions
-func (r *BookingRepository) generateBookingRef() string {
-	return fmt.Sprintf("BK%d", time.Now().UnixNano())
-}
+// GetBookingStatuses looks up the status of every booking matching one of ids
+// or refs in a single query, ordered by id. Unknown ids and refs are omitted.
+func (r *BookingRepository) GetBookingStatuses(ctx context.Context, ids []int, refs []string) ([]*models.BookingStatusEntry, error) {
+	query := `
+		SELECT id, booking_ref, status, expires_at 
+		FROM bookings 
+		WHERE id = ANY($1) OR booking_ref = ANY($2) 
+		ORDER BY id`
 
-func joinInts(ints []int, sep string) string {
-	if len(ints) == 0 {
-		return ""
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids), pq.Array(refs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up bookings: %w", err)
 	}
+	defer rows.Close()
 
-	result := fmt.Sprintf("%d", ints[0])
-	for i := 1; i < len(ints); i++ {
-		result += sep + fmt.Sprintf("%d", ints[i])
+	now := time.Now()
+	entries := []*models.BookingStatusEntry{}
+	for rows.Next() {
+		var booking models.Booking
+		if err := rows.Scan(&booking.ID, &booking.BookingRef, &booking.Status, &booking.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan booking: %w", err)
+		}
+		booking.SetSecondsRemaining(now)
+
+		entries = append(entries, &models.BookingStatusEntry{
+			ID:               booking.ID,
+			BookingRef:       booking.BookingRef,
+			Status:           booking.Status,
+			ExpiresAt:        booking.ExpiresAt,
+			SecondsRemaining: booking.SecondsRemaining,
+		})
 	}
-	return result
+
+	return entries, rows.Err()
 }
 
-func parseTicketIDs(ticketIDsStr string) []int {
-	// Parser for PostgreSQL array format: {1,2,3} or {10,11,12}
-	if len(ticketIDsStr) < 3 {
-		return []int{}
+// ListBookings retrieves a page of bookings matching filter, newest first,
+// along with the number of matching bookings across all pages
+func (r *BookingRepository) ListBookings(ctx context.Context, filter models.BookingFilter, limit, offset int) ([]*models.Booking, int, error) {
+	where, args := bookingFilterClause(filter)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM bookings ` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bookings: %w", err)
 	}
 
-	// Remove braces
-	ticketIDsStr = ticketIDsStr[1 : len(ticketIDsStr)-1]
+	query := fmt.Sprintf(`
+		SELECT `+bookingColumns+`
+		FROM bookings b 
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
 
-	// Handle empty array
-	if len(ticketIDsStr) == 0 {
-		return []int{}
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bookings: %w", err)
 	}
+	defer rows.Close()
 
-	var ticketIDs []int
-	var currentNumber string
-
-	for _, char := range ticketIDsStr {
-		if char >= '0' && char <= '9' {
-			currentNumber += string(char)
-		} else if char == ',' {
-			if currentNumber != "" {
-				if id, err := strconv.Atoi(currentNumber); err == nil {
-					ticketIDs = append(ticketIDs, id)
-				}
-				currentNumber = ""
-			}
+	bookings := []*models.Booking{}
+	for rows.Next() {
+		var booking models.Booking
+		if err := scanBooking(rows, &booking); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan booking: %w", err)
 		}
+		bookings = append(bookings, &booking)
+	}
+
+	return bookings, total, rows.Err()
+}
+
+// bookingFilterClause builds the WHERE clause and arguments for a booking filter
+func bookingFilterClause(filter models.BookingFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Status != "" {
+		args = append(args, string(filter.Status))
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
 	}
 
-	// Don't forget the last number
-	if currentNumber != "" {
-		if id, err := strconv.Atoi(currentNumber); err == nil {
-			ticketIDs = append(ticketIDs, id)
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// maxBookingRefAttempts bounds how many references a booking tries before
+// giving up; collisions only become likely with a short BOOKING_REF_LENGTH
+const maxBookingRefAttempts = 5
+
+// generateBookingRef returns BOOKING_REF_PREFIX followed by either the current
+// time in nanoseconds or, when BOOKING_REF_LENGTH is set, that many random
+// characters from BOOKING_REF_CHARSET. Uniqueness is enforced by the insert.
+func (r *BookingRepository) generateBookingRef() (string, error) {
+	prefix := r.config.App.BookingRefPrefix
+	length := r.config.App.BookingRefLength
+	if length == 0 {
+		return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano()), nil
+	}
+
+	charset := r.config.App.BookingRefCharset
+	ref := make([]byte, length)
+	for i := range ref {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate booking reference: %w", err)
 		}
+		ref[i] = charset[n.Int64()]
 	}
+	return prefix + string(ref), nil
+}
 
-	return ticketIDs
+// toInts converts a scanned INTEGER[] column into the model's []int
+func toInts(values pq.Int64Array) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
 }
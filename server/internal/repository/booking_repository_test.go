@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// TestTicketIDArrayScan reads ticket_ids the way scanBooking does, from the
+// text form Postgres sends for an integer[] column
+func TestTicketIDArrayScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []int
+	}{
+		{"empty", "{}", []int{}},
+		{"single", "{42}", []int{42}},
+		{"many", "{1,2,3,4,5,6,7,8,9,10}", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"negative and large", "{-1,2147483647,9007199254740993}", []int{-1, 2147483647, 9007199254740993}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ticketIDArray pq.Int64Array
+			if err := ticketIDArray.Scan([]byte(tt.value)); err != nil {
+				t.Fatalf("scan %s: %v", tt.value, err)
+			}
+			if got := toInts(ticketIDArray); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toInts = %v, want %v", got, tt.want)
+			}
+
+			// Writing the ids back through pq.Array gives the same array
+			value, err := pq.Array(tt.want).Value()
+			if err != nil {
+				t.Fatalf("pq.Array(%v): %v", tt.want, err)
+			}
+			if value != tt.value {
+				t.Errorf("pq.Array(%v) = %v, want %s", tt.want, value, tt.value)
+			}
+		})
+	}
+}
+
+func TestTicketIDArrayScanMalformed(t *testing.T) {
+	for _, value := range []string{"{1,x,3}", "1,2,3", "{1,2"} {
+		var ticketIDArray pq.Int64Array
+		if err := ticketIDArray.Scan([]byte(value)); err == nil {
+			t.Errorf("scan %q = %v, want an error rather than dropped entries", value, ticketIDArray)
+		}
+	}
+}
+
+func TestToIntsNil(t *testing.T) {
+	// A NULL ticket_ids scans to a nil array
+	if got := toInts(nil); got == nil || len(got) != 0 {
+		t.Errorf("toInts(nil) = %#v, want an empty slice", got)
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// assertNothingBooked checks an event is back to where it was before any
+// booking: every seat available, the counter full and no booking rows
+func assertNothingBooked(t *testing.T, bookingRepo *BookingRepository, event *models.Event) {
+	t.Helper()
+
+	counts := ticketCounts(t, bookingRepo.db, event.ID)
+	if counts[models.TicketAvailable] != event.TotalTickets {
+		t.Errorf("ticket statuses = %v, want all %d available", counts, event.TotalTickets)
+	}
+	if available := availableCounter(t, bookingRepo.db, event.ID); available != event.TotalTickets {
+		t.Errorf("available_tickets = %d, want %d", available, event.TotalTickets)
+	}
+
+	var bookings int
+	err := bookingRepo.db.QueryRowContext(context.Background(),
+		`SELECT COUNT(*) FROM bookings WHERE event_id = $1`, event.ID).Scan(&bookings)
+	if err != nil {
+		t.Fatalf("count bookings: %v", err)
+	}
+	if bookings != 0 {
+		t.Errorf("%d bookings were written, want none", bookings)
+	}
+}
+
+// TestPostgresCancelledBookingRollsBack cancels the request after the booking
+// queries have all run but before the transaction commits
+func TestPostgresCancelledBookingRollsBack(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 3, 2500)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := bookingRepo.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := bookingRepo.bookTicketsWithLock(ctx, tx, guestRequest(event.ID, 2, models.BookingModeAuto, 0)); err != nil {
+			t.Fatalf("book: %v", err)
+		}
+		// The client goes away while the handler is still running
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WithTransaction = %v, want context.Canceled", err)
+	}
+	assertNothingBooked(t, bookingRepo, event)
+}
+
+// TestPostgresCancelledBookingReleasesLock cancels a booking that is waiting
+// for the event row lock, and checks it leaves nothing behind and doesn't
+// keep other bookings waiting
+func TestPostgresCancelledBookingReleasesLock(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 3, 2500)
+	background := context.Background()
+
+	// Another transaction holds the event row, so the booking blocks on FOR UPDATE
+	blocker, err := bookingRepo.db.BeginTx(background, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer blocker.Rollback()
+	if _, err := blocker.ExecContext(background, `SELECT id FROM events WHERE id = $1 FOR UPDATE`, event.ID); err != nil {
+		t.Fatalf("lock event: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(background, 200*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err = bookingRepo.BookTickets(ctx, guestRequest(event.ID, 1, models.BookingModeAuto, 0))
+	if err == nil {
+		t.Fatal("booking succeeded while the event row was locked")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("cancelled booking took %s to return", elapsed)
+	}
+	if ctx.Err() == nil {
+		t.Errorf("booking failed before the context was done: %v", err)
+	}
+
+	if err := blocker.Rollback(); err != nil {
+		t.Fatalf("release event: %v", err)
+	}
+	assertNothingBooked(t, bookingRepo, event)
+
+	// The abandoned transaction must not still be holding anything
+	next, nextCancel := context.WithTimeout(background, 5*time.Second)
+	defer nextCancel()
+	booking, err := bookingRepo.BookTickets(next, guestRequest(event.ID, 1, models.BookingModeAuto, 1))
+	if err != nil {
+		t.Fatalf("booking after the cancelled one: %v", err)
+	}
+	if booking.Quantity != 1 {
+		t.Errorf("booked %d seats, want 1", booking.Quantity)
+	}
+}
+
+// TestPostgresWithTransactionCancelledBeforeCommit checks WithTransaction
+// refuses to commit once ctx is done, even when fn itself succeeded
+func TestPostgresWithTransactionCancelledBeforeCommit(t *testing.T) {
+	_, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 1, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := eventRepo.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE events SET name = 'renamed' WHERE id = $1`, event.ID); err != nil {
+			return err
+		}
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WithTransaction = %v, want context.Canceled", err)
+	}
+
+	var name string
+	if err := eventRepo.db.QueryRowContext(context.Background(),
+		`SELECT name FROM events WHERE id = $1`, event.ID).Scan(&name); err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if name != event.Name {
+		t.Errorf("name = %q after a cancelled transaction, want %q", name, event.Name)
+	}
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/milinddethe15/ticket-booking/internal/expiry"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// runConcurrently starts workers goroutines at once, each calling book with its
+// index, and returns the bookings made and the errors of the rest
+func runConcurrently(workers int, book func(n int) (*models.Booking, error)) ([]*models.Booking, []error) {
+	var mu sync.Mutex
+	var bookings []*models.Booking
+	var errs []error
+
+	var ready, done sync.WaitGroup
+	start := make(chan struct{})
+	for n := 0; n < workers; n++ {
+		ready.Add(1)
+		done.Add(1)
+		go func(n int) {
+			defer done.Done()
+			ready.Done()
+			<-start
+
+			booking, err := book(n)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			bookings = append(bookings, booking)
+		}(n)
+	}
+	ready.Wait()
+	close(start)
+	done.Wait()
+
+	return bookings, errs
+}
+
+// assertSoldOut checks that exactly seats tickets went to bookings, none twice,
+// and that every failed booking failed for want of seats
+func assertSoldOut(t *testing.T, bookingRepo *BookingRepository, event *models.Event, bookings []*models.Booking, errs []error) {
+	t.Helper()
+
+	sold := 0
+	for _, booking := range bookings {
+		sold += booking.Quantity
+	}
+	if sold != event.TotalTickets {
+		t.Errorf("booked %d seats, want exactly %d", sold, event.TotalTickets)
+	}
+	for _, err := range errs {
+		if !isSeatShortage(err) {
+			t.Errorf("booking failed for another reason than a seat shortage: %v", err)
+		}
+	}
+
+	counts := ticketCounts(t, bookingRepo.db, event.ID)
+	if counts[models.TicketReserved] != event.TotalTickets || counts[models.TicketAvailable] != 0 || counts[models.TicketLocked] != 0 {
+		t.Errorf("ticket statuses = %v, want all %d reserved", counts, event.TotalTickets)
+	}
+	if available := availableCounter(t, bookingRepo.db, event.ID); available != 0 {
+		t.Errorf("available_tickets = %d, want 0", available)
+	}
+	assertNoSeatSoldTwice(t, bookingRepo.db, event.ID)
+}
+
+// TestConcurrentAutoBookingsNoOversell has many more bookers than seats race
+// for a small event through bookTicketsWithLock
+func TestConcurrentAutoBookingsNoOversell(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 10, 2500)
+	ctx := context.Background()
+
+	bookings, errs := runConcurrently(50, func(n int) (*models.Booking, error) {
+		return bookDirect(ctx, bookingRepo, guestRequest(event.ID, 1, models.BookingModeAuto, n))
+	})
+
+	if len(bookings) != event.TotalTickets {
+		t.Errorf("%d bookings succeeded, want %d", len(bookings), event.TotalTickets)
+	}
+	assertSoldOut(t, bookingRepo, event, bookings, errs)
+}
+
+// TestConcurrentLockedBookingsNoOversell has several sessions compete for each
+// seat's lock and then book whatever they managed to lock
+func TestConcurrentLockedBookingsNoOversell(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 6, 2500)
+	ctx := context.Background()
+
+	tickets, _, err := eventRepo.GetAllTickets(ctx, event.ID, "", event.TotalTickets)
+	if err != nil {
+		t.Fatalf("list tickets: %v", err)
+	}
+
+	bookings, errs := runConcurrently(24, func(n int) (*models.Booking, error) {
+		session := fmt.Sprintf("session-%d", n)
+		if err := eventRepo.LockSeat(ctx, event.ID, tickets[n%len(tickets)].SeatNo, session); err != nil {
+			// Another session locked it first; booking must then find nothing
+			request := guestRequest(event.ID, 1, models.BookingModeWithLock, n)
+			request.SessionID = session
+			if _, err := bookDirect(ctx, bookingRepo, request); err == nil {
+				return nil, fmt.Errorf("session %s booked a seat it never locked", session)
+			}
+			return nil, &InsufficientLockedSeatsError{Requested: 1}
+		}
+
+		request := guestRequest(event.ID, 1, models.BookingModeWithLock, n)
+		request.SessionID = session
+		return bookDirect(ctx, bookingRepo, request)
+	})
+
+	assertSoldOut(t, bookingRepo, event, bookings, errs)
+}
+
+// TestConcurrentBookingsRace is small enough to run under -race and goes
+// through BookTickets with a lock expiry scheduler, so the in-process state
+// shared between requests is exercised as well as the database locking
+func TestConcurrentBookingsRace(t *testing.T) {
+	database := testDB(t)
+	scheduler := expiry.NewScheduler(testLogger())
+	bookingRepo := NewBookingRepository(database, scheduler, nil, testLogger(), testConfig())
+	eventRepo := NewEventRepository(database, nil, scheduler, testLogger(), testConfig())
+	event := createTestEvent(t, eventRepo, 4, 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.Run(ctx, eventRepo.ExpireSeatLock)
+
+	bookings, errs := runConcurrently(12, func(n int) (*models.Booking, error) {
+		return bookingRepo.BookTickets(ctx, guestRequest(event.ID, 1, models.BookingModeAuto, n))
+	})
+
+	assertSoldOut(t, bookingRepo, event, bookings, errs)
+}
+
+// TestConcurrentTinyEventNoOversell races bookers for events with one or two
+// seats, where a single extra seat sold would be an oversell
+func TestConcurrentTinyEventNoOversell(t *testing.T) {
+	tests := []struct {
+		name     string
+		seats    int
+		quantity int
+		workers  int
+	}{
+		{"one seat", 1, 1, 20},
+		{"two seats, one each", 2, 1, 20},
+		{"two seats, two each", 2, 2, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookingRepo, eventRepo := testRepos(t)
+			event := createTestEvent(t, eventRepo, tt.seats, 2500)
+			ctx := context.Background()
+
+			bookings, errs := runConcurrently(tt.workers, func(n int) (*models.Booking, error) {
+				return bookDirect(ctx, bookingRepo, guestRequest(event.ID, tt.quantity, models.BookingModeAuto, n))
+			})
+
+			if want := tt.seats / tt.quantity; len(bookings) != want {
+				t.Errorf("%d bookings succeeded, want %d", len(bookings), want)
+			}
+			assertSoldOut(t, bookingRepo, event, bookings, errs)
+		})
+	}
+}
+
+// TestPostgresAvailableCounterBackstop books against a counter that has
+// drifted below the ticket rows; the conditional decrement must refuse
+func TestPostgresAvailableCounterBackstop(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 3, 2500)
+	ctx := context.Background()
+
+	if _, err := bookingRepo.db.ExecContext(ctx, `UPDATE events SET available_tickets = 1 WHERE id = $1`, event.ID); err != nil {
+		t.Fatalf("set counter: %v", err)
+	}
+
+	_, err := bookDirect(ctx, bookingRepo, guestRequest(event.ID, 2, models.BookingModeAuto, 0))
+	if err == nil || !isSeatShortage(err) {
+		t.Fatalf("booking 2 seats against a counter of 1 = %v, want insufficient tickets", err)
+	}
+
+	if counts := ticketCounts(t, bookingRepo.db, event.ID); counts[models.TicketAvailable] != event.TotalTickets {
+		t.Errorf("ticket statuses = %v, want all %d still available", counts, event.TotalTickets)
+	}
+	if available := availableCounter(t, bookingRepo.db, event.ID); available != 1 {
+		t.Errorf("available_tickets = %d, want 1", available)
+	}
+}
+
+// TestConcurrentAutoBookersGetDisjointSeats has two auto-mode bookers take
+// seats at the same moment; both must succeed, with no seat in common
+func TestConcurrentAutoBookersGetDisjointSeats(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 10, 2500)
+	ctx := context.Background()
+
+	bookings, errs := runConcurrently(2, func(n int) (*models.Booking, error) {
+		return bookDirect(ctx, bookingRepo, guestRequest(event.ID, 3, models.BookingModeAuto, n))
+	})
+	if len(errs) > 0 {
+		t.Fatalf("bookings failed: %v", errs)
+	}
+
+	seen := make(map[int]bool)
+	for _, booking := range bookings {
+		if len(booking.TicketIDs) != 3 {
+			t.Errorf("booking %d has tickets %v, want 3", booking.ID, booking.TicketIDs)
+		}
+		for _, id := range booking.TicketIDs {
+			if seen[id] {
+				t.Errorf("ticket %d is in both bookings", id)
+			}
+			seen[id] = true
+		}
+	}
+
+	counts := ticketCounts(t, bookingRepo.db, event.ID)
+	if counts[models.TicketReserved] != 6 || counts[models.TicketAvailable] != 4 {
+		t.Errorf("ticket statuses = %v, want 6 reserved and 4 available", counts)
+	}
+	if available := availableCounter(t, bookingRepo.db, event.ID); available != 4 {
+		t.Errorf("available_tickets = %d, want 4", available)
+	}
+	assertNoSeatSoldTwice(t, bookingRepo.db, event.ID)
+}
+
+// TestConcurrentCancelAndExpire races a cancel against the expiry of the same
+// pending booking; exactly one must win, and the seats come back only once
+func TestConcurrentCancelAndExpire(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 4, 2500)
+	ctx := context.Background()
+
+	for round := 0; round < 10; round++ {
+		booking, err := bookDirect(ctx, bookingRepo, guestRequest(event.ID, 2, models.BookingModeAuto, round))
+		if err != nil {
+			t.Fatalf("round %d: book: %v", round, err)
+		}
+
+		_, errs := runConcurrently(2, func(n int) (*models.Booking, error) {
+			if n == 0 {
+				return booking, bookingRepo.CancelBooking(ctx, booking.ID)
+			}
+			return booking, bookingRepo.ForceExpireBooking(ctx, booking.ID, "sweep")
+		})
+
+		if len(errs) != 1 {
+			t.Fatalf("round %d: %d of cancel and expire failed, want exactly 1: %v", round, len(errs), errs)
+		}
+		var transitionErr *InvalidTransitionError
+		if !errors.As(errs[0], &transitionErr) {
+			t.Errorf("round %d: loser failed with %v, want an InvalidTransitionError", round, errs[0])
+		}
+
+		counts := ticketCounts(t, bookingRepo.db, event.ID)
+		if counts[models.TicketAvailable] != event.TotalTickets {
+			t.Fatalf("round %d: ticket statuses = %v, want all %d available", round, counts, event.TotalTickets)
+		}
+		if available := availableCounter(t, bookingRepo.db, event.ID); available != event.TotalTickets {
+			t.Fatalf("round %d: available_tickets = %d, want %d", round, available, event.TotalTickets)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// redeemCoupon validates a coupon for an event and counts one use of it. The
+// usage increment is a single conditional UPDATE, so concurrent bookings can
+// never push a coupon past max_uses; the caller's transaction rolls the use
+// back if the booking fails.
+func redeemCoupon(ctx context.Context, tx *sql.Tx, code string, eventID int) (*models.Coupon, error) {
+	redeemQuery := `
+		UPDATE coupons 
+		SET times_used = times_used + 1 
+		WHERE code = $1 AND ` + couponUsable + ` 
+		RETURNING code, COALESCE(percent_off, 0), COALESCE(amount_off, 0), COALESCE(currency, '')`
+
+	var coupon models.Coupon
+	err := tx.QueryRowContext(ctx, redeemQuery, code, eventID).Scan(
+		&coupon.Code,
+		&coupon.PercentOff,
+		&coupon.AmountOff,
+		&coupon.Currency,
+	)
+	if err == sql.ErrNoRows {
+		return nil, couponRejection(ctx, tx, code, eventID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem coupon: %w", err)
+	}
+
+	return &coupon, nil
+}
+
+// couponUsable matches a coupon row that event $2 could redeem right now
+const couponUsable = `active 
+		AND (expires_at IS NULL OR expires_at > NOW()) 
+		AND (max_uses IS NULL OR times_used < max_uses) 
+		AND (event_id IS NULL OR event_id = $2)`
+
+// checkCoupon is redeemCoupon without counting a use, for quotes
+func checkCoupon(ctx context.Context, tx *sql.Tx, code string, eventID int) (*models.Coupon, error) {
+	query := `
+		SELECT code, COALESCE(percent_off, 0), COALESCE(amount_off, 0), COALESCE(currency, '') 
+		FROM coupons 
+		WHERE code = $1 AND ` + couponUsable
+
+	var coupon models.Coupon
+	err := tx.QueryRowContext(ctx, query, code, eventID).Scan(
+		&coupon.Code,
+		&coupon.PercentOff,
+		&coupon.AmountOff,
+		&coupon.Currency,
+	)
+	if err == sql.ErrNoRows {
+		return nil, couponRejection(ctx, tx, code, eventID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check coupon: %w", err)
+	}
+
+	return &coupon, nil
+}
+
+// lookupCoupon reads a coupon's discount terms without redeeming it
+func lookupCoupon(ctx context.Context, tx *sql.Tx, code string) (*models.Coupon, error) {
+	query := `
+		SELECT code, COALESCE(percent_off, 0), COALESCE(amount_off, 0), COALESCE(currency, '') 
+		FROM coupons 
+		WHERE code = $1`
+
+	var coupon models.Coupon
+	err := tx.QueryRowContext(ctx, query, code).Scan(
+		&coupon.Code,
+		&coupon.PercentOff,
+		&coupon.AmountOff,
+		&coupon.Currency,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coupon: %w", err)
+	}
+	return &coupon, nil
+}
+
+// couponRejection explains why redeemCoupon matched no row
+func couponRejection(ctx context.Context, tx *sql.Tx, code string, eventID int) error {
+	query := `
+		SELECT active, expires_at, max_uses, times_used, event_id, NOW() 
+		FROM coupons 
+		WHERE code = $1`
+
+	var active bool
+	var expiresAt sql.NullTime
+	var maxUses sql.NullInt64
+	var timesUsed int64
+	var couponEventID sql.NullInt64
+	var now time.Time
+
+	err := tx.QueryRowContext(ctx, query, code).Scan(&active, &expiresAt, &maxUses, &timesUsed, &couponEventID, &now)
+	if err == sql.ErrNoRows {
+		return &InvalidCouponError{Code: code, Reason: "does not exist"}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check coupon: %w", err)
+	}
+
+	reason := "cannot be applied"
+	switch {
+	case !active:
+		reason = "is no longer active"
+	case expiresAt.Valid && !now.Before(expiresAt.Time):
+		reason = "has expired"
+	case maxUses.Valid && timesUsed >= maxUses.Int64:
+		reason = "has reached its usage limit"
+	case couponEventID.Valid && int(couponEventID.Int64) != eventID:
+		reason = "is not valid for this event"
+	}
+	return &InvalidCouponError{Code: code, Reason: reason}
+}
+
+// couponDiscount prices a coupon against a booking total in the given currency
+func couponDiscount(coupon *models.Coupon, total models.Money, currency string) (models.Money, error) {
+	if coupon.PercentOff == 0 && coupon.Currency != currency {
+		return 0, &InvalidCouponError{
+			Code:   coupon.Code,
+			Reason: fmt.Sprintf("is in %s but the event is priced in %s", coupon.Currency, currency),
+		}
+	}
+	return coupon.Discount(total), nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// remediationSeatLimit caps how many alternative seats an error suggests
+const remediationSeatLimit = 20
+
+// InsufficientLockedSeatsError is returned when a booking asks for more seats
+// than the session currently has locked
+type InsufficientLockedSeatsError struct {
+	Requested      int      `json:"requested"`
+	Locked         int      `json:"locked_by_session"`
+	AvailableSeats []string `json:"available_seats"`
+}
+
+func (e *InsufficientLockedSeatsError) Error() string {
+	return fmt.Sprintf("insufficient locked seats for booking. Found %d locked seats, need %d. Please select seats first", e.Locked, e.Requested)
+}
+
+// InventoryInconsistencyError is returned when an event's available_tickets
+// counter says seats are left but the event has no available or locked tickets.
+// The data is corrupt rather than the request wrong; reconciling the event's
+// availability corrects the counter.
+type InventoryInconsistencyError struct {
+	EventID  int `json:"event_id"`
+	Recorded int `json:"recorded_available"`
+	Actual   int `json:"actual_available"`
+}
+
+func (e *InventoryInconsistencyError) Error() string {
+	return fmt.Sprintf("event %d ticket inventory is inconsistent: available_tickets is %d but %d tickets are available", e.EventID, e.Recorded, e.Actual)
+}
+
+// TicketRebuildConflictError is returned when an event's tickets can't be
+// rebuilt because existing tickets don't belong to its labelling scheme or
+// repeat a seat. BookedSeats are those among them held by a booking.
+type TicketRebuildConflictError struct {
+	EventID     int      `json:"event_id"`
+	Seats       []string `json:"conflicting_seats"`
+	BookedSeats []string `json:"booked_seats"`
+}
+
+func (e *TicketRebuildConflictError) Error() string {
+	return fmt.Sprintf("event %d has %d tickets outside its seat labels (%d booked)", e.EventID, len(e.Seats), len(e.BookedSeats))
+}
+
+// SalesPausedError is returned when seats are locked, held, booked or added
+// to a booking on an event whose organizer has paused sales
+type SalesPausedError struct {
+	EventID int `json:"event_id"`
+}
+
+func (e *SalesPausedError) Error() string {
+	return fmt.Sprintf("sales paused for event %d", e.EventID)
+}
+
+// PaymentVerificationError is returned when the payment gateway does not
+// accept a booking's payment reference; the booking stays pending
+type PaymentVerificationError struct {
+	BookingID  int    `json:"booking_id"`
+	PaymentRef string `json:"payment_ref"`
+	Reason     string `json:"reason"`
+}
+
+func (e *PaymentVerificationError) Error() string {
+	return fmt.Sprintf("payment %q for booking %d could not be verified: %s", e.PaymentRef, e.BookingID, e.Reason)
+}
+
+// PaymentVerifierUnavailableError is returned when a booking is confirmed with
+// a payment reference but no payment verifier is configured to check it
+type PaymentVerifierUnavailableError struct {
+	BookingID int `json:"booking_id"`
+}
+
+func (e *PaymentVerifierUnavailableError) Error() string {
+	return fmt.Sprintf("cannot verify the payment for booking %d: payment verification is not configured", e.BookingID)
+}
+
+// InvalidCouponError is returned when a booking's coupon code cannot be applied
+type InvalidCouponError struct {
+	Code   string `json:"coupon_code"`
+	Reason string `json:"reason"`
+}
+
+func (e *InvalidCouponError) Error() string {
+	return fmt.Sprintf("coupon %q %s", e.Code, e.Reason)
+}
+
+// SessionLockLimitError is returned when a session tries to lock more seats on
+// an event than MAX_LOCKS_PER_SESSION allows
+type SessionLockLimitError struct {
+	Limit     int `json:"limit"`
+	Held      int `json:"held"`
+	Requested int `json:"requested"`
+}
+
+func (e *SessionLockLimitError) Error() string {
+	return fmt.Sprintf("session lock limit reached: holding %d of %d seats, requested %d more", e.Held, e.Limit, e.Requested)
+}
+
+// TicketConfirmationError is returned when some of a booking's tickets are no
+// longer reserved at confirmation, usually because they were released while
+// the booking sat unpaid
+type TicketConfirmationError struct {
+	BookingID int   `json:"booking_id"`
+	TicketIDs []int `json:"unconfirmed_ticket_ids"`
+}
+
+func (e *TicketConfirmationError) Error() string {
+	return fmt.Sprintf("booking %d can no longer be confirmed; seats were released (tickets %v are no longer reserved)", e.BookingID, e.TicketIDs)
+}
+
+// InvalidTransitionError is returned when a booking cannot move from its
+// current status to the requested one, e.g. confirming a cancelled booking
+type InvalidTransitionError struct {
+	BookingID int                  `json:"booking_id"`
+	From      models.BookingStatus `json:"from"`
+	To        models.BookingStatus `json:"to"`
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("booking %d cannot change from %s to %s", e.BookingID, e.From, e.To)
+}
+
+// MaxHoldDurationError is returned when a session tries to refresh a seat lock
+// it has already kept for MAX_HOLD_DURATION
+type MaxHoldDurationError struct {
+	SeatNo   string        `json:"seat_no"`
+	LockedAt time.Time     `json:"locked_at"`
+	MaxHold  time.Duration `json:"-"`
+}
+
+func (e *MaxHoldDurationError) Error() string {
+	return fmt.Sprintf("seat %s has been locked since %s and reached the maximum hold duration of %s", e.SeatNo, e.LockedAt.Format(time.RFC3339), e.MaxHold)
+}
+
+// AlreadyCheckedInError is returned when a ticket that was already admitted is
+// scanned again, so gate staff can see when and by whom
+type AlreadyCheckedInError struct {
+	TicketID    int       `json:"ticket_id"`
+	CheckedInAt time.Time `json:"checked_in_at"`
+	CheckedInBy string    `json:"checked_in_by"`
+}
+
+func (e *AlreadyCheckedInError) Error() string {
+	return fmt.Sprintf("ticket %d already checked in at %s", e.TicketID, e.CheckedInAt.Format(time.RFC3339))
+}
@@ -3,39 +3,57 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 
+	"github.com/milinddethe15/ticket-booking/internal/cache"
 	"github.com/milinddethe15/ticket-booking/internal/config"
 	"github.com/milinddethe15/ticket-booking/internal/db"
+	"github.com/milinddethe15/ticket-booking/internal/expiry"
 	"github.com/milinddethe15/ticket-booking/internal/models"
+	"github.com/milinddethe15/ticket-booking/internal/seating"
 )
 
 type EventRepository struct {
-	db     *db.DB
-	logger *logrus.Logger
-	config *config.Config
+	db         *db.DB
+	cache      *cache.EventCache
+	lockExpiry *expiry.Scheduler
+	logger     *logrus.Logger
+	config     *config.Config
 }
 
-func NewEventRepository(database *db.DB, logger *logrus.Logger, cfg *config.Config) *EventRepository {
+// NewEventRepository creates an event repository; eventCache and lockExpiry
+// may be nil to disable caching and prompt lock expiry respectively
+func NewEventRepository(database *db.DB, eventCache *cache.EventCache, lockExpiry *expiry.Scheduler, logger *logrus.Logger, cfg *config.Config) *EventRepository {
 	return &EventRepository{
-		db:     database,
-		logger: logger,
-		config: cfg,
+		db:         database,
+		cache:      eventCache,
+		lockExpiry: lockExpiry,
+		logger:     logger,
+		config:     cfg,
 	}
 }
 
-// GetEvent retrieves an event by ID
-func (r *EventRepository) GetEvent(ctx context.Context, eventID int) (*models.Event, error) {
-	query := `
-		SELECT id, name, description, venue, start_time, end_time, 
-			   total_tickets, available_tickets, price, created_at, updated_at
-		FROM events 
-		WHERE id = $1`
+// eventColumns lists the columns read by scanEvent, in scan order
+const eventColumns = `id, name, COALESCE(description, ''), COALESCE(venue, ''), start_time, end_time,
+	total_tickets, available_tickets, price, currency,
+	COALESCE(seat_label_format, ''), COALESCE(seat_rows, 0), COALESCE(max_per_booking, 0),
+	COALESCE(max_per_user, 0), COALESCE(external_ref, ''), sales_close_offset, COALESCE(series_id, 0),
+	COALESCE(seat_lock_duration, 0), is_sales_open, COALESCE(image_url, ''), COALESCE(banner_url, ''), created_at, updated_at`
 
-	var event models.Event
-	err := r.db.QueryRowContext(ctx, query, eventID).Scan(
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEvent scans eventColumns, followed by any extra columns into extra
+func scanEvent(row rowScanner, event *models.Event, extra ...interface{}) error {
+	dest := []interface{}{
 		&event.ID,
 		&event.Name,
 		&event.Description,
@@ -45,9 +63,55 @@ func (r *EventRepository) GetEvent(ctx context.Context, eventID int) (*models.Ev
 		&event.TotalTickets,
 		&event.AvailableTickets,
 		&event.Price,
+		&event.Currency,
+		&event.SeatLabelFormat,
+		&event.SeatRows,
+		&event.MaxPerBooking,
+		&event.MaxPerUser,
+		&event.ExternalRef,
+		&event.SalesCloseOffset,
+		&event.SeriesID,
+		&event.SeatLockDuration,
+		&event.SalesOpen,
+		&event.ImageURL,
+		&event.BannerURL,
 		&event.CreatedAt,
 		&event.UpdatedAt,
-	)
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	event.Status = event.StatusAt(time.Now())
+	return nil
+}
+
+// GetEvent retrieves an event by ID. Like the other event and ticket listings
+// it reads from the replica when one is configured, so it may lag a write by
+// the replication delay; booking paths re-read the event on the primary.
+func (r *EventRepository) GetEvent(ctx context.Context, eventID int) (*models.Event, error) {
+	if event, ok := r.cache.GetEvent(ctx, eventID); ok {
+		return event, nil
+	}
+	return r.loadEvent(ctx, r.db.Reader(), eventID)
+}
+
+// GetEventFromPrimary retrieves an event from the primary, bypassing the cache,
+// for callers that must see their own writes or an event created moments ago.
+// The row it reads replaces whatever the cache held.
+func (r *EventRepository) GetEventFromPrimary(ctx context.Context, eventID int) (*models.Event, error) {
+	return r.loadEvent(ctx, r.db, eventID)
+}
+
+// loadEvent reads an event from database and caches it
+func (r *EventRepository) loadEvent(ctx context.Context, database *db.DB, eventID int) (*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events 
+		WHERE id = $1`
+
+	var event models.Event
+	err := scanEvent(database.QueryRowContext(ctx, query, eventID), &event)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -56,19 +120,49 @@ func (r *EventRepository) GetEvent(ctx context.Context, eventID int) (*models.Ev
 		return nil, err
 	}
 
+	r.cache.SetEvent(ctx, &event)
 	return &event, nil
 }
 
-// GetEvents retrieves all events with pagination
-func (r *EventRepository) GetEvents(ctx context.Context, limit, offset int) ([]*models.Event, error) {
+// GetEventByExternalRef retrieves the event created with the given external reference
+func (r *EventRepository) GetEventByExternalRef(ctx context.Context, externalRef string) (*models.Event, error) {
 	query := `
-		SELECT id, name, description, venue, start_time, end_time, 
-			   total_tickets, available_tickets, price, created_at, updated_at
+		SELECT ` + eventColumns + `
 		FROM events 
-		ORDER BY start_time ASC
-		LIMIT $1 OFFSET $2`
+		WHERE external_ref = $1`
+
+	var event models.Event
+	err := scanEvent(r.db.QueryRowContext(ctx, query, externalRef), &event)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		return nil, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	return &event, nil
+}
+
+// GetEvents retrieves events matching filter with pagination. Filtered
+// listings are reporting queries and skip the cache.
+func (r *EventRepository) GetEvents(ctx context.Context, filter models.EventFilter, limit, offset int) ([]*models.Event, error) {
+	cacheable := filter.IsZero()
+	if cacheable {
+		if events, ok := r.cache.GetEvents(ctx, limit, offset); ok {
+			return events, nil
+		}
+	}
+
+	where, args := eventFilterClause(filter)
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT `+eventColumns+`
+		FROM events %s
+		ORDER BY start_time ASC, id ASC
+		LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -77,82 +171,101 @@ func (r *EventRepository) GetEvents(ctx context.Context, limit, offset int) ([]*
 	var events []*models.Event
 	for rows.Next() {
 		var event models.Event
-		err := rows.Scan(
-			&event.ID,
-			&event.Name,
-			&event.Description,
-			&event.Venue,
-			&event.StartTime,
-			&event.EndTime,
-			&event.TotalTickets,
-			&event.AvailableTickets,
-			&event.Price,
-			&event.CreatedAt,
-			&event.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanEvent(rows, &event); err != nil {
 			return nil, err
 		}
 		events = append(events, &event)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
+	if cacheable {
+		r.cache.SetEvents(ctx, limit, offset, events)
+	}
 	return events, nil
 }
 
+// eventFilterClause builds the WHERE clause for GetEvents; placeholders are
+// numbered from $1 so callers append their own arguments after these
+func eventFilterClause(filter models.EventFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// uniqueViolation is the Postgres SQLSTATE for a unique constraint violation
+const uniqueViolation = "23505"
+
+// ticketInsertBatchSize caps how many tickets CreateEvent inserts per statement
+const ticketInsertBatchSize = 1000
+
 // CreateEvent creates a new event with tickets
 func (r *EventRepository) CreateEvent(ctx context.Context, event *models.Event) (*models.Event, error) {
 	var createdEvent *models.Event
 
 	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
-		// Insert event
-		insertEventQuery := `
-			INSERT INTO events (name, description, venue, start_time, end_time, total_tickets, available_tickets, price, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-			RETURNING id, created_at, updated_at`
+		var err error
+		createdEvent, err = r.insertEvent(ctx, tx, event)
+		return err
+	})
 
-		var eventID int
-		err := tx.QueryRowContext(ctx, insertEventQuery,
-			event.Name,
-			event.Description,
-			event.Venue,
-			event.StartTime,
-			event.EndTime,
-			event.TotalTickets,
-			event.TotalTickets, // available_tickets = total_tickets initially
-			event.Price,
-		).Scan(&eventID, &event.CreatedAt, &event.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to create event: %w", err)
+	r.cache.Invalidate(ctx, createdEvent.ID)
+
+	r.logger.WithFields(logrus.Fields{
+		"event_id":      createdEvent.ID,
+		"event_name":    createdEvent.Name,
+		"total_tickets": createdEvent.TotalTickets,
+	}).Info("Event created successfully")
+
+	return createdEvent, nil
+}
+
+// CreateSeries creates one event per start time from base, all with their own
+// tickets and linked by a new series, in a single transaction
+func (r *EventRepository) CreateSeries(ctx context.Context, base *models.Event, frequency string, startTimes []time.Time) (*models.EventSeries, error) {
+	series := &models.EventSeries{Name: base.Name, Frequency: frequency}
+	duration := base.EndTime.Sub(base.StartTime)
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		insertSeriesQuery := `
+			INSERT INTO event_series (name, frequency, created_at, updated_at)
+			VALUES ($1, $2, NOW(), NOW())
+			RETURNING id, created_at`
+
+		if err := tx.QueryRowContext(ctx, insertSeriesQuery, series.Name, series.Frequency).Scan(&series.ID, &series.CreatedAt); err != nil {
+			return fmt.Errorf("failed to create series: %w", err)
 		}
 
-		// Create tickets for the event
-		insertTicketQuery := `
-			INSERT INTO tickets (event_id, seat_no, status, created_at, updated_at)
-			VALUES ($1, $2, 'available', NOW(), NOW())`
+		for _, start := range startTimes {
+			occurrence := *base
+			occurrence.StartTime = start
+			occurrence.EndTime = start.Add(duration)
+			occurrence.SeriesID = series.ID
 
-		for i := 1; i <= event.TotalTickets; i++ {
-			seatNo := fmt.Sprintf("S%03d", i)
-			_, err = tx.ExecContext(ctx, insertTicketQuery, eventID, seatNo)
+			created, err := r.insertEvent(ctx, tx, &occurrence)
 			if err != nil {
-				return fmt.Errorf("failed to create ticket %s: %w", seatNo, err)
+				return fmt.Errorf("failed to create occurrence at %s: %w", start.Format(time.RFC3339), err)
 			}
+			series.Events = append(series.Events, created)
 		}
-
-		createdEvent = &models.Event{
-			ID:               eventID,
-			Name:             event.Name,
-			Description:      event.Description,
-			Venue:            event.Venue,
-			StartTime:        event.StartTime,
-			EndTime:          event.EndTime,
-			TotalTickets:     event.TotalTickets,
-			AvailableTickets: event.TotalTickets,
-			Price:            event.Price,
-			CreatedAt:        event.CreatedAt,
-			UpdatedAt:        event.UpdatedAt,
-		}
-
 		return nil
 	})
 
@@ -160,25 +273,253 @@ func (r *EventRepository) CreateEvent(ctx context.Context, event *models.Event)
 		return nil, err
 	}
 
+	for _, event := range series.Events {
+		r.cache.Invalidate(ctx, event.ID)
+	}
+
 	r.logger.WithFields(logrus.Fields{
-		"event_id":      createdEvent.ID,
-		"event_name":    createdEvent.Name,
-		"total_tickets": createdEvent.TotalTickets,
-	}).Info("Event created successfully")
+		"series_id":     series.ID,
+		"event_name":    series.Name,
+		"frequency":     series.Frequency,
+		"occurrences":   len(series.Events),
+		"total_tickets": base.TotalTickets,
+	}).Info("Event series created successfully")
+
+	return series, nil
+}
+
+// GetSeries retrieves a series with its occurrences in start time order
+func (r *EventRepository) GetSeries(ctx context.Context, seriesID int) (*models.EventSeries, error) {
+	series := &models.EventSeries{ID: seriesID, Events: []*models.Event{}}
+
+	seriesQuery := `SELECT name, frequency, created_at FROM event_series WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, seriesQuery, seriesID).Scan(&series.Name, &series.Frequency, &series.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("series not found")
+		}
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events 
+		WHERE series_id = $1
+		ORDER BY start_time ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.Event
+		if err := scanEvent(rows, &event); err != nil {
+			return nil, err
+		}
+		series.Events = append(series.Events, &event)
+	}
+
+	return series, rows.Err()
+}
+
+// userEventsQuery totals a user's pending and confirmed bookings per event;
+// cancelled and expired bookings no longer hold any tickets
+const userEventsQuery = `
+	WITH user_bookings AS (
+		SELECT event_id,
+			COUNT(*) AS booking_count,
+			SUM(quantity) AS ticket_count,
+			COUNT(*) FILTER (WHERE status = 'confirmed') AS confirmed_count,
+			SUM(total_amount) AS booked_amount,
+			array_agg(id ORDER BY id) AS booking_ids,
+			MAX(created_at) AS last_booked_at
+		FROM bookings
+		WHERE user_id = $1 AND status IN ('pending', 'confirmed')
+		GROUP BY event_id
+	)`
+
+// GetUserEvents retrieves a page of the events a user has pending or confirmed
+// bookings for, each with a summary of those bookings, along with the number
+// of such events across all pages. Upcoming events come soonest first and past
+// events most recent first.
+func (r *EventRepository) GetUserEvents(ctx context.Context, userID int, timeframe models.EventTimeframe, limit, offset int) ([]*models.UserEvent, int, error) {
+	where, order := "", "start_time ASC, id ASC"
+	switch timeframe {
+	case models.TimeframeUpcoming:
+		where = "WHERE end_time > NOW()"
+	case models.TimeframePast:
+		where, order = "WHERE end_time <= NOW()", "start_time DESC, id DESC"
+	}
+
+	var total int
+	countQuery := userEventsQuery + `
+		SELECT COUNT(*)
+		FROM events 
+		JOIN user_bookings ON user_bookings.event_id = events.id 
+		` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count user events: %w", err)
+	}
+
+	query := userEventsQuery + `
+		SELECT ` + eventColumns + `,
+			booking_count, ticket_count, confirmed_count, booked_amount, booking_ids, last_booked_at
+		FROM events 
+		JOIN user_bookings ON user_bookings.event_id = events.id 
+		` + where + `
+		ORDER BY ` + order + `
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list user events: %w", err)
+	}
+	defer rows.Close()
+
+	userEvents := []*models.UserEvent{}
+	for rows.Next() {
+		var event models.Event
+		var summary models.UserBookingsSummary
+		var bookingIDs pq.Int64Array
+		err := scanEvent(rows, &event,
+			&summary.Count, &summary.Tickets, &summary.Confirmed, &summary.TotalAmount, &bookingIDs, &summary.LastBookedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user event: %w", err)
+		}
+
+		summary.Currency = event.Currency
+		summary.BookingIDs = make([]int, len(bookingIDs))
+		for i, id := range bookingIDs {
+			summary.BookingIDs[i] = int(id)
+		}
+		userEvents = append(userEvents, &models.UserEvent{Event: &event, Bookings: summary})
+	}
+
+	return userEvents, total, rows.Err()
+}
+
+// insertEvent inserts an event row and all of its tickets inside tx
+func (r *EventRepository) insertEvent(ctx context.Context, tx *sql.Tx, event *models.Event) (*models.Event, error) {
+	// Insert event
+	insertEventQuery := `
+		INSERT INTO events (name, description, venue, start_time, end_time, total_tickets, available_tickets, price, currency,
+			seat_label_format, seat_rows, max_per_booking, max_per_user, external_ref, sales_close_offset, series_id, seat_lock_duration,
+			image_url, banner_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, 0), NULLIF($12, 0), NULLIF($13, 0), NULLIF($14, ''), $15, NULLIF($16, 0), NULLIF($17, 0),
+			NULLIF($18, ''), NULLIF($19, ''), NOW(), NOW())
+		RETURNING id, created_at, updated_at`
+
+	var eventID int
+	err := tx.QueryRowContext(ctx, insertEventQuery,
+		event.Name,
+		event.Description,
+		event.Venue,
+		event.StartTime,
+		event.EndTime,
+		event.TotalTickets,
+		event.TotalTickets, // available_tickets = total_tickets initially
+		event.Price,
+		event.Currency,
+		event.SeatLabelFormat,
+		event.SeatRows,
+		event.MaxPerBooking,
+		event.MaxPerUser,
+		event.ExternalRef,
+		event.SalesCloseOffset,
+		event.SeriesID,
+		event.SeatLockDuration,
+		event.ImageURL,
+		event.BannerURL,
+	).Scan(&eventID, &event.CreatedAt, &event.UpdatedAt)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "idx_events_external_ref" {
+			return nil, fmt.Errorf("event with external_ref %q already exists", event.ExternalRef)
+		}
+		return nil, fmt.Errorf("failed to create event: %w", err)
+	}
+
+	// Explicit labels win; otherwise derive them from the labelling scheme
+	seatLabels := event.SeatLabels
+	if len(seatLabels) == 0 {
+		seatLabels, err = seating.GenerateLabels(event.SeatLabelFormat, event.SeatRows, event.TotalTickets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate seat labels: %w", err)
+		}
+	}
+
+	if err := insertTickets(ctx, tx, eventID, seatLabels); err != nil {
+		return nil, err
+	}
+
+	createdEvent := &models.Event{
+		ID:               eventID,
+		Name:             event.Name,
+		Description:      event.Description,
+		Venue:            event.Venue,
+		StartTime:        event.StartTime,
+		EndTime:          event.EndTime,
+		TotalTickets:     event.TotalTickets,
+		AvailableTickets: event.TotalTickets,
+		Price:            event.Price,
+		Currency:         event.Currency,
+		SeatLabelFormat:  event.SeatLabelFormat,
+		SeatRows:         event.SeatRows,
+		MaxPerBooking:    event.MaxPerBooking,
+		MaxPerUser:       event.MaxPerUser,
+		ExternalRef:      event.ExternalRef,
+		SalesCloseOffset: event.SalesCloseOffset,
+		SeriesID:         event.SeriesID,
+		SeatLockDuration: event.SeatLockDuration,
+		SalesOpen:        true,
+		ImageURL:         event.ImageURL,
+		BannerURL:        event.BannerURL,
+		CreatedAt:        event.CreatedAt,
+		UpdatedAt:        event.UpdatedAt,
+	}
+	createdEvent.Status = createdEvent.StatusAt(time.Now())
 
 	return createdEvent, nil
 }
 
-// GetAvailableTickets retrieves available tickets for an event
-func (r *EventRepository) GetAvailableTickets(ctx context.Context, eventID int, limit int) ([]*models.Ticket, error) {
+// insertTickets creates available tickets for the given seats in batches,
+// keeping label order so ticket ids still follow seat order
+func insertTickets(ctx context.Context, tx *sql.Tx, eventID int, seatLabels []string) error {
+	insertTicketQuery := `
+		INSERT INTO tickets (event_id, seat_no, status, created_at, updated_at)
+		SELECT $1, s.seat_no, 'available', NOW(), NOW()
+		FROM unnest($2::text[]) WITH ORDINALITY AS s(seat_no, ord)
+		ORDER BY s.ord`
+
+	for start := 0; start < len(seatLabels); start += ticketInsertBatchSize {
+		end := start + ticketInsertBatchSize
+		if end > len(seatLabels) {
+			end = len(seatLabels)
+		}
+
+		_, err := tx.ExecContext(ctx, insertTicketQuery, eventID, pq.Array(seatLabels[start:end]))
+		if err != nil {
+			return fmt.Errorf("failed to create tickets %s to %s: %w", seatLabels[start], seatLabels[end-1], err)
+		}
+	}
+	return nil
+}
+
+// GetTickets lists an event's tickets in seat order. An empty status returns
+// tickets in every state.
+func (r *EventRepository) GetTickets(ctx context.Context, eventID int, status models.TicketStatus, limit, offset int) ([]*models.Ticket, error) {
+	// id breaks ties so pages never overlap or skip seats
 	query := `
 		SELECT id, event_id, seat_no, status, created_at, updated_at
 		FROM tickets 
-		WHERE event_id = $1 AND status = 'available'
-		ORDER BY seat_no
-		LIMIT $2`
+		WHERE event_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY seat_no, id
+		LIMIT $3 OFFSET $4`
 
-	rows, err := r.db.QueryContext(ctx, query, eventID, limit)
+	rows, err := r.db.Reader().QueryContext(ctx, query, eventID, string(status), limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -201,25 +542,47 @@ func (r *EventRepository) GetAvailableTickets(ctx context.Context, eventID int,
 		tickets = append(tickets, &ticket)
 	}
 
-	return tickets, nil
+	return tickets, rows.Err()
+}
+
+// CountTickets counts an event's tickets in the given status; an empty status counts all of them
+func (r *EventRepository) CountTickets(ctx context.Context, eventID int, status models.TicketStatus) (int, error) {
+	query := `
+		SELECT COUNT(*) 
+		FROM tickets 
+		WHERE event_id = $1 AND ($2 = '' OR status = $2)`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, eventID, string(status)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+	return count, nil
+}
+
+// GetAvailableTickets retrieves available tickets for an event
+func (r *EventRepository) GetAvailableTickets(ctx context.Context, eventID int, limit, offset int) ([]*models.Ticket, error) {
+	return r.GetTickets(ctx, eventID, models.TicketAvailable, limit, offset)
 }
 
-// GetAllTickets retrieves all tickets for an event (including sold/reserved) for UI display
-func (r *EventRepository) GetAllTickets(ctx context.Context, eventID int, limit int) ([]*models.Ticket, error) {
+// GetAllTickets retrieves tickets for an event in every status for UI display,
+// in seat order starting after the seat label after ("" starts from the
+// first seat). more reports whether seats remain beyond this page.
+func (r *EventRepository) GetAllTickets(ctx context.Context, eventID int, after string, limit int) (tickets []*models.Ticket, more bool, err error) {
+	// seat_no is unique per event, so it is a stable keyset cursor on its own
 	query := `
 		SELECT id, event_id, seat_no, status, created_at, updated_at
 		FROM tickets 
-		WHERE event_id = $1
+		WHERE event_id = $1 AND ($2 = '' OR seat_no > $2)
 		ORDER BY seat_no
-		LIMIT $2`
+		LIMIT $3`
 
-	rows, err := r.db.QueryContext(ctx, query, eventID, limit)
+	// Fetch one extra row to learn whether the list goes on
+	rows, err := r.db.Reader().QueryContext(ctx, query, eventID, after, limit+1)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	defer rows.Close()
 
-	var tickets []*models.Ticket
 	for rows.Next() {
 		var ticket models.Ticket
 		err := rows.Scan(
@@ -231,108 +594,1177 @@ func (r *EventRepository) GetAllTickets(ctx context.Context, eventID int, limit
 			&ticket.UpdatedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		tickets = append(tickets, &ticket)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
 
-	return tickets, nil
+	if len(tickets) > limit {
+		return tickets[:limit], true, nil
+	}
+	return tickets, false, nil
 }
 
-// LockSeat temporarily locks a seat for seat selection (3 minutes)
-func (r *EventRepository) LockSeat(ctx context.Context, eventID int, seatNo string, userSession string) error {
-	return r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
-		// Check if seat is available
-		var currentStatus string
-		checkQuery := `SELECT status FROM tickets WHERE event_id = $1 AND seat_no = $2 FOR UPDATE`
-
-		r.logger.WithFields(logrus.Fields{
-			"event_id": eventID,
-			"seat_no":  seatNo,
-			"session":  userSession,
-		}).Debug("Attempting to lock seat")
+// QuoteBooking prices a prospective booking of the requested seats, or of
+// Quantity seats, the same way booking them would. Nothing is locked and a
+// coupon is checked without being used up. Seats must belong to the event but
+// may be taken; the booking itself is what checks availability.
+func (r *EventRepository) QuoteBooking(ctx context.Context, eventID int, request *models.QuoteRequest) (*models.Quote, error) {
+	event, err := r.GetEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
 
-		err := tx.QueryRowContext(ctx, checkQuery, eventID, seatNo).Scan(&currentStatus)
-		if err != nil {
-			r.logger.WithError(err).WithFields(logrus.Fields{
-				"event_id": eventID,
-				"seat_no":  seatNo,
-			}).Error("Seat not found during lock attempt")
-			return fmt.Errorf("seat not found: %w", err)
-		}
+	quote := &models.Quote{
+		EventID:    eventID,
+		Quantity:   request.Quantity,
+		UnitPrice:  event.Price,
+		CouponCode: request.CouponCode,
+		Currency:   event.Currency,
+	}
+	if len(request.SeatNumbers) > 0 {
+		quote.Quantity = len(request.SeatNumbers)
+	}
+	if event.MaxPerBooking > 0 && quote.Quantity > event.MaxPerBooking {
+		return nil, fmt.Errorf("quantity exceeds the maximum of %d tickets per booking for this event", event.MaxPerBooking)
+	}
 
-		r.logger.WithFields(logrus.Fields{
-			"event_id":       eventID,
-			"seat_no":        seatNo,
-			"current_status": currentStatus,
-		}).Debug("Current seat status")
+	err = r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if len(request.SeatNumbers) > 0 {
+			rows, err := tx.QueryContext(ctx, `SELECT seat_no FROM tickets WHERE event_id = $1 AND seat_no = ANY($2)`,
+				eventID, pq.Array(request.SeatNumbers))
+			if err != nil {
+				return fmt.Errorf("failed to look up seats: %w", err)
+			}
+			found := make(map[string]bool)
+			for rows.Next() {
+				var seatNo string
+				if err := rows.Scan(&seatNo); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to look up seats: %w", err)
+				}
+				found[seatNo] = true
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("failed to look up seats: %w", err)
+			}
 
-		if currentStatus != "available" {
-			return fmt.Errorf("seat is no longer available (current status: %s)", currentStatus)
+			var unknown []string
+			for _, seatNo := range request.SeatNumbers {
+				if !found[seatNo] {
+					unknown = append(unknown, seatNo)
+				}
+				quote.Seats = append(quote.Seats, models.QuoteSeat{SeatNo: seatNo, Price: event.Price})
+			}
+			if len(unknown) > 0 {
+				return fmt.Errorf("unknown seats for this event: %s", strings.Join(unknown, ", "))
+			}
 		}
 
-		// Lock the seat temporarily
-		lockQuery := `UPDATE tickets SET status = 'locked', updated_at = NOW() WHERE event_id = $1 AND seat_no = $2`
-		result, err := tx.ExecContext(ctx, lockQuery, eventID, seatNo)
-		if err != nil {
-			return fmt.Errorf("failed to lock seat: %w", err)
+		var coupon *models.Coupon
+		if request.CouponCode != "" {
+			var err error
+			if coupon, err = checkCoupon(ctx, tx, request.CouponCode, eventID); err != nil {
+				return err
+			}
 		}
 
-		rowsAffected, _ := result.RowsAffected()
-		if rowsAffected == 0 {
-			return fmt.Errorf("seat was just taken by another user")
+		price, err := priceBooking(event.Price, quote.Quantity, coupon, event.Currency, &r.config.App)
+		if err != nil {
+			return err
 		}
-
-		r.logger.WithFields(logrus.Fields{
-			"event_id": eventID,
-			"seat_no":  seatNo,
-			"session":  userSession,
-		}).Info("Seat locked temporarily")
-
+		quote.PriceBreakdown = *price
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return quote, nil
 }
 
-// UnlockSeat releases a temporarily locked seat
-func (r *EventRepository) UnlockSeat(ctx context.Context, eventID int, seatNo string) error {
-	query := `UPDATE tickets SET status = 'available', updated_at = NOW() WHERE event_id = $1 AND seat_no = $2 AND status = 'locked'`
+// CheckAvailability reports whether quantity seats are currently available
+// without locking anything, and what they would cost before any coupon
+func (r *EventRepository) CheckAvailability(ctx context.Context, eventID int, quantity int) (*models.AvailabilityCheck, error) {
+	query := `
+		SELECT e.price, e.currency,
+			   (SELECT COUNT(*) FROM tickets t WHERE t.event_id = e.id AND t.status = 'available')
+		FROM events e
+		WHERE e.id = $1`
+
+	check := models.AvailabilityCheck{
+		EventID:  eventID,
+		Quantity: quantity,
+	}
 
-	_, err := r.db.ExecContext(ctx, query, eventID, seatNo)
+	var price models.Money
+	err := r.db.QueryRowContext(ctx, query, eventID).Scan(&price, &check.Currency, &check.AvailableCount)
 	if err != nil {
-		return fmt.Errorf("failed to unlock seat: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		return nil, err
 	}
 
-	r.logger.WithFields(logrus.Fields{
-		"event_id": eventID,
-		"seat_no":  seatNo,
-	}).Info("Seat unlocked")
+	check.Available = check.AvailableCount >= quantity
 
-	return nil
+	// Priced as a booking without a coupon would be, fees and tax included
+	estimate, err := priceBooking(price, quantity, nil, check.Currency, &r.config.App)
+	if err != nil {
+		return nil, err
+	}
+	check.EstimatedTotal = estimate.Total
+
+	return &check, nil
 }
 
-// CleanupExpiredLocks removes locks older than the configured seat lock duration
-func (r *EventRepository) CleanupExpiredLocks(ctx context.Context) error {
-	// Use the configurable seat lock duration instead of hardcoded '3 minutes'
-	lockDurationMinutes := int(r.config.App.SeatLockDuration.Minutes())
+// CountAvailableTickets counts an event's available tickets. The result may be
+// served from a cache that is at most a couple of seconds old.
+func (r *EventRepository) CountAvailableTickets(ctx context.Context, eventID int) (*models.AvailableCount, error) {
+	if count, ok := r.cache.GetAvailableCount(ctx, eventID); ok {
+		return count, nil
+	}
 
-	query := fmt.Sprintf(`
-		UPDATE tickets 
-		SET status = 'available', updated_at = NOW()
-		WHERE status = 'locked' 
-		AND updated_at < NOW() - INTERVAL '%d minutes'`, lockDurationMinutes)
+	query := `
+		SELECT (SELECT COUNT(*) FROM tickets t WHERE t.event_id = e.id AND t.status = 'available'), NOW()
+		FROM events e
+		WHERE e.id = $1`
 
-	result, err := r.db.ExecContext(ctx, query)
+	count := models.AvailableCount{EventID: eventID}
+	err := r.db.QueryRowContext(ctx, query, eventID).Scan(&count.Available, &count.CountedAt)
 	if err != nil {
-		return fmt.Errorf("failed to cleanup expired locks: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		return nil, err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected > 0 {
-		r.logger.WithFields(logrus.Fields{
-			"seats_unlocked": rowsAffected,
-			"lock_duration":  r.config.App.SeatLockDuration,
-		}).Info("Cleaned up expired seat locks")
-	}
+	r.cache.SetAvailableCount(ctx, &count)
+	return &count, nil
+}
+
+// FastAvailableCount reads an event's available seats from the available_tickets
+// counter instead of counting every available ticket. The counter still
+// includes locked seats, so those are subtracted; only the handful of locked
+// rows is scanned. The result is exact while the counter is in step with the
+// tickets; drift is corrected by the next reconcile pass, and in the meantime
+// the count is clamped to the event's capacity and never goes negative.
+func (r *EventRepository) FastAvailableCount(ctx context.Context, eventID int) (*models.AvailableCount, error) {
+	query := `
+		SELECT GREATEST(
+				   LEAST(e.available_tickets, e.total_tickets)
+				   - (SELECT COUNT(*) FROM tickets t WHERE t.event_id = e.id AND t.status = 'locked'),
+				   0),
+			   NOW()
+		FROM events e
+		WHERE e.id = $1`
+
+	count := models.AvailableCount{EventID: eventID, Fast: true}
+	err := r.db.QueryRowContext(ctx, query, eventID).Scan(&count.Available, &count.CountedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		return nil, err
+	}
+
+	return &count, nil
+}
+
+// GetSeatMap retrieves every seat of an event grouped by section and row
+func (r *EventRepository) GetSeatMap(ctx context.Context, eventID int) (*models.SeatMap, error) {
+	event, err := r.GetEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, _, err := r.GetAllTickets(ctx, eventID, "", event.TotalTickets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+
+	return seating.BuildSeatMap(event, tickets), nil
+}
+
+// GetSeatMapImage renders an event's seat map as a PNG at the given scale. The
+// image may be served from a cache that is a few seconds old.
+func (r *EventRepository) GetSeatMapImage(ctx context.Context, eventID int, scale int) ([]byte, error) {
+	if image, ok := r.cache.GetSeatMapImage(ctx, eventID, scale); ok {
+		return image, nil
+	}
+
+	seatMap, err := r.GetSeatMap(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := seating.RenderPNG(seatMap, scale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render seat map: %w", err)
+	}
+
+	r.cache.SetSeatMapImage(ctx, eventID, scale, image)
+	return image, nil
+}
+
+// SuggestSeats proposes quantity available seats, adjacent in one row when
+// possible. Nothing is locked; the seats can still be taken by someone else.
+func (r *EventRepository) SuggestSeats(ctx context.Context, eventID int, quantity int) (*models.SeatSuggestion, error) {
+	seatMap, err := r.GetSeatMap(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	seats, adjacent := seating.Suggest(seatMap, quantity)
+	if seats == nil {
+		return nil, fmt.Errorf("not enough available seats: requested %d, %d available", quantity, seatMap.Counts.Available)
+	}
+
+	return &models.SeatSuggestion{
+		EventID:  eventID,
+		Quantity: quantity,
+		Seats:    seats,
+		Adjacent: adjacent,
+	}, nil
+}
+
+// LockSeat temporarily locks a seat for seat selection, for the event's seat
+// lock duration (SEAT_LOCK_DURATION unless the event overrides it)
+func (r *EventRepository) LockSeat(ctx context.Context, eventID int, seatNo string, userSession string) error {
+	var ticketID int
+	var lockSeconds int
+	// When the lock (re)started by the database clock, which the expiry
+	// queries measure against
+	var lockedSince time.Time
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if r.config.App.MaxLocksPerSession > 0 {
+			if err := serializeSession(ctx, tx, eventID, userSession); err != nil {
+				return err
+			}
+		}
+
+		// Check if seat is available
+		var currentStatus string
+		var lockedBy string
+		var held bool
+		var eventEnd time.Time
+		var salesOpen bool
+		var lockedAt sql.NullTime
+		var now time.Time
+		checkQuery := `
+			SELECT t.id, t.status, COALESCE(t.locked_by, ''), t.hold_id IS NOT NULL, t.locked_at, e.end_time,
+				e.is_sales_open, COALESCE(e.seat_lock_duration, 0), NOW()
+			FROM tickets t
+			JOIN events e ON e.id = t.event_id
+			WHERE t.event_id = $1 AND t.seat_no = $2
+			FOR UPDATE OF t`
+
+		r.logger.WithFields(logrus.Fields{
+			"event_id": eventID,
+			"seat_no":  seatNo,
+			"session":  userSession,
+		}).Debug("Attempting to lock seat")
+
+		err := tx.QueryRowContext(ctx, checkQuery, eventID, seatNo).Scan(&ticketID, &currentStatus, &lockedBy, &held, &lockedAt, &eventEnd, &salesOpen, &lockSeconds, &now)
+		if err == sql.ErrNoRows {
+			return r.missingSeatError(ctx, tx, eventID, seatNo)
+		}
+		if err != nil {
+			r.logger.WithError(err).WithFields(logrus.Fields{
+				"event_id": eventID,
+				"seat_no":  seatNo,
+			}).Error("Seat not found during lock attempt")
+			return fmt.Errorf("seat not found: %w", err)
+		}
+
+		r.logger.WithFields(logrus.Fields{
+			"event_id":       eventID,
+			"seat_no":        seatNo,
+			"current_status": currentStatus,
+		}).Debug("Current seat status")
+
+		if !now.Before(eventEnd) {
+			return fmt.Errorf("event has already ended")
+		}
+		if !salesOpen {
+			return &SalesPausedError{EventID: eventID}
+		}
+
+		// A repeated lock from the session that already holds the seat is a
+		// retry, not a conflict: succeed and restart the lock timer, unless the
+		// session has already kept the seat for MAX_HOLD_DURATION
+		if currentStatus == string(models.TicketLocked) && !held && lockedBy == userSession {
+			maxHold := r.config.App.MaxHoldDuration
+			if maxHold > 0 && lockedAt.Valid && !now.Before(lockedAt.Time.Add(maxHold)) {
+				return &MaxHoldDurationError{SeatNo: seatNo, LockedAt: lockedAt.Time, MaxHold: maxHold}
+			}
+
+			refreshQuery := `UPDATE tickets SET updated_at = NOW() WHERE event_id = $1 AND seat_no = $2 RETURNING NOW()`
+			if err := tx.QueryRowContext(ctx, refreshQuery, eventID, seatNo).Scan(&lockedSince); err != nil {
+				return fmt.Errorf("failed to refresh seat lock: %w", err)
+			}
+
+			r.logger.WithFields(logrus.Fields{
+				"event_id": eventID,
+				"seat_no":  seatNo,
+				"session":  userSession,
+			}).Debug("Seat lock refreshed")
+			return nil
+		}
+
+		if currentStatus != "available" {
+			return fmt.Errorf("seat is no longer available (current status: %s)", currentStatus)
+		}
+
+		if err := checkSessionLockLimit(ctx, tx, eventID, userSession, 1, r.config.App.MaxLocksPerSession, r.config.App.SeatLockDuration); err != nil {
+			return err
+		}
+
+		// Lock the seat temporarily
+		lockQuery := `UPDATE tickets SET status = 'locked', locked_by = $3, locked_at = NOW(), updated_at = NOW() WHERE event_id = $1 AND seat_no = $2 RETURNING NOW()`
+		err = tx.QueryRowContext(ctx, lockQuery, eventID, seatNo, userSession).Scan(&lockedSince)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("seat was just taken by another user")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock seat: %w", err)
+		}
+
+		r.logger.WithFields(logrus.Fields{
+			"event_id": eventID,
+			"seat_no":  seatNo,
+			"session":  userSession,
+		}).Info("Seat locked temporarily")
+
+		return nil
+	})
+
+	if err == nil {
+		lockDuration := (&models.Event{SeatLockDuration: lockSeconds}).LockDuration(r.config.App.SeatLockDuration)
+		r.lockExpiry.Schedule(ticketID, userSession, lockedSince.Add(lockDuration+r.config.App.LockExpiryGrace))
+	}
+	return err
+}
+
+// missingSeatError explains why a seat lookup found nothing: the event is
+// missing, the label can't exist under the event's labelling scheme, or the
+// label is well-formed but simply not a seat of this event. The lock handler
+// checks the scheme before locking; this covers the cases it couldn't.
+func (r *EventRepository) missingSeatError(ctx context.Context, tx *sql.Tx, eventID int, seatNo string) error {
+	var format string
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(seat_label_format, '') FROM events WHERE id = $1`, eventID).Scan(&format)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("event not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check event: %w", err)
+	}
+
+	if !seating.MatchesFormat(format, seatNo) {
+		return fmt.Errorf("invalid seat label %q: seats for this event are labelled %s", seatNo, format)
+	}
+	return fmt.Errorf("seat not found")
+}
+
+// UnlockSeat releases a temporarily locked seat
+func (r *EventRepository) UnlockSeat(ctx context.Context, eventID int, seatNo string, userSession string) error {
+	// Only the locking session may release a seat. Seats belonging to a hold
+	// are released through the hold's expiry instead.
+	query := `
+		UPDATE tickets 
+		SET status = 'available', locked_by = NULL, updated_at = NOW() 
+		WHERE event_id = $1 AND seat_no = $2 AND status = 'locked' AND hold_id IS NULL 
+		AND locked_by = $3
+		RETURNING id`
+
+	rows, err := r.db.QueryContext(ctx, query, eventID, seatNo, userSession)
+	if err != nil {
+		return fmt.Errorf("failed to unlock seat: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ticketID int
+		if err := rows.Scan(&ticketID); err != nil {
+			return fmt.Errorf("failed to unlock seat: %w", err)
+		}
+		r.lockExpiry.Cancel(ticketID)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to unlock seat: %w", err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"event_id": eventID,
+		"seat_no":  seatNo,
+	}).Info("Seat unlocked")
+
+	return nil
+}
+
+// ExpireSeatLock releases one seat lock if it has really expired, grace period
+// included. It is the release callback for the lock expiry scheduler, so it
+// re-checks ownership and age in the database rather than trusting the
+// scheduler's entry.
+func (r *EventRepository) ExpireSeatLock(ctx context.Context, ticketID int, session string) error {
+	query := `
+		UPDATE tickets t
+		SET status = 'available', locked_by = NULL, updated_at = NOW() 
+		FROM events e
+		WHERE t.id = $1 AND e.id = t.event_id
+		AND t.status = 'locked' AND t.hold_id IS NULL AND t.locked_by = $2 
+		AND t.updated_at <= NOW() - make_interval(secs => $4) - ` + lockDurationInterval("e.seat_lock_duration", 3)
+
+	result, err := r.db.ExecContext(ctx, query, ticketID, session, r.config.App.SeatLockDuration.Seconds(), r.config.App.LockExpiryGrace.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to expire seat lock: %w", err)
+	}
+
+	if released, _ := result.RowsAffected(); released > 0 {
+		r.logger.WithFields(logrus.Fields{
+			"ticket_id": ticketID,
+			"session":   session,
+		}).Debug("Expired seat lock released")
+	}
+	return nil
+}
+
+// CleanupExpiredLocks removes locks older than their event's seat lock duration,
+// or the configured default for events without one, and locks first taken more
+// than MAX_HOLD_DURATION ago however recently they were refreshed. Both limits
+// are extended by LOCK_EXPIRY_GRACE, so a booking sent just as the lock runs
+// out still finds its seats.
+func (r *EventRepository) CleanupExpiredLocks(ctx context.Context) error {
+	query := `
+		UPDATE tickets t
+		SET status = 'available', locked_by = NULL, updated_at = NOW()
+		FROM events e
+		WHERE e.id = t.event_id
+		AND t.status = 'locked' 
+		AND t.hold_id IS NULL
+		AND (t.updated_at < NOW() - make_interval(secs => $3) - ` + lockDurationInterval("e.seat_lock_duration", 1) + `
+			OR ($2::float8 > 0 AND t.locked_at < NOW() - make_interval(secs => $3) - make_interval(secs => $2)))`
+
+	result, err := r.db.ExecContext(ctx, query, r.config.App.SeatLockDuration.Seconds(), r.config.App.MaxHoldDuration.Seconds(),
+		r.config.App.LockExpiryGrace.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired locks: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected > 0 {
+		r.logger.WithFields(logrus.Fields{
+			"seats_unlocked":        rowsAffected,
+			"default_lock_duration": r.config.App.SeatLockDuration,
+			"max_hold_duration":     r.config.App.MaxHoldDuration,
+			"grace":                 r.config.App.LockExpiryGrace,
+		}).Info("Cleaned up expired seat locks")
+	}
 
 	return nil
 }
+
+// GetSeatLocks lists an event's locked seats with who holds them and until when
+func (r *EventRepository) GetSeatLocks(ctx context.Context, eventID int) ([]*models.SeatLock, error) {
+	if err := r.checkEventExists(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT t.id, t.seat_no, COALESCE(t.locked_by, ''), COALESCE(t.hold_id, ''), t.locked_at,
+			COALESCE(h.expires_at, t.updated_at + ` + lockDurationInterval("e.seat_lock_duration", 2) + `) AS locked_until,
+			NOW()
+		FROM tickets t
+		JOIN events e ON e.id = t.event_id
+		LEFT JOIN holds h ON h.id = t.hold_id
+		WHERE t.event_id = $1 AND t.status = 'locked'
+		ORDER BY t.seat_no`
+
+	rows, err := r.db.QueryContext(ctx, query, eventID, r.config.App.SeatLockDuration.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seat locks: %w", err)
+	}
+	defer rows.Close()
+
+	locks := []*models.SeatLock{}
+	for rows.Next() {
+		var lock models.SeatLock
+		var lockedAt sql.NullTime
+		var now time.Time
+		if err := rows.Scan(&lock.TicketID, &lock.SeatNo, &lock.SessionID, &lock.HoldID, &lockedAt, &lock.LockedUntil, &now); err != nil {
+			return nil, fmt.Errorf("failed to scan seat lock: %w", err)
+		}
+		if lockedAt.Valid {
+			lock.LockedAt = &lockedAt.Time
+		}
+		lock.Overdue = now.After(lock.LockedUntil)
+		locks = append(locks, &lock)
+	}
+
+	return locks, rows.Err()
+}
+
+// sessionLocksQuery selects the unexpired seat locks of session $1, with $2
+// as the default seat lock duration; callers narrow and order it
+var sessionLocksQuery = `
+	SELECT event_id, id, seat_no, hold_id, locked_until,
+		EXTRACT(EPOCH FROM locked_until - NOW())::int AS seconds_remaining
+	FROM (
+		SELECT t.event_id, t.id, t.seat_no, COALESCE(t.hold_id, '') AS hold_id,
+			COALESCE(h.expires_at, t.updated_at + ` + lockDurationInterval("e.seat_lock_duration", 2) + `) AS locked_until
+		FROM tickets t
+		JOIN events e ON e.id = t.event_id
+		LEFT JOIN holds h ON h.id = t.hold_id
+		WHERE t.status = 'locked' AND t.locked_by = $1
+	) held
+	WHERE locked_until > NOW()`
+
+// GetSessionHolds returns the seats of an event the session has locked and not
+// yet lost to expiry, with the tentative price of booking them
+func (r *EventRepository) GetSessionHolds(ctx context.Context, eventID int, session string) (*models.SessionHolds, error) {
+	var price models.Money
+	holds := &models.SessionHolds{EventID: eventID, Seats: []*models.HeldSeat{}}
+	err := r.db.QueryRowContext(ctx, `SELECT price, currency FROM events WHERE id = $1`, eventID).Scan(&price, &holds.Currency)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("event not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	if session == "" {
+		return holds, nil
+	}
+
+	query := sessionLocksQuery + ` AND event_id = $3 ORDER BY seat_no`
+	rows, err := r.db.QueryContext(ctx, query, session, r.config.App.SeatLockDuration.Seconds(), eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session holds: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seat models.HeldSeat
+		var seatEventID int
+		if err := rows.Scan(&seatEventID, &seat.TicketID, &seat.SeatNo, &seat.HoldID, &seat.LockedUntil, &seat.SecondsRemaining); err != nil {
+			return nil, fmt.Errorf("failed to scan held seat: %w", err)
+		}
+		holds.Seats = append(holds.Seats, &seat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list session holds: %w", err)
+	}
+
+	holds.TotalPrice = price.Mul(len(holds.Seats))
+	return holds, nil
+}
+
+// ListSessionHolds returns a session's unexpired seat locks across all events,
+// one entry per event in event id order. limit and offset page over events, and
+// the total is the number of events with seats held.
+func (r *EventRepository) ListSessionHolds(ctx context.Context, session string, limit, offset int) ([]*models.SessionHolds, int, error) {
+	lockDuration := r.config.App.SeatLockDuration.Seconds()
+
+	var total int
+	countQuery := `SELECT COUNT(DISTINCT event_id) FROM (` + sessionLocksQuery + `) live`
+	if err := r.db.QueryRowContext(ctx, countQuery, session, lockDuration).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count session holds: %w", err)
+	}
+
+	query := `
+		WITH live AS (` + sessionLocksQuery + `),
+		page AS (
+			SELECT DISTINCT event_id FROM live ORDER BY event_id LIMIT $3 OFFSET $4
+		)
+		SELECT live.event_id, live.id, live.seat_no, live.hold_id, live.locked_until, live.seconds_remaining,
+			e.price, e.currency
+		FROM live
+		JOIN page ON page.event_id = live.event_id
+		JOIN events e ON e.id = live.event_id
+		ORDER BY live.event_id, live.seat_no`
+
+	rows, err := r.db.QueryContext(ctx, query, session, lockDuration, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list session holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := []*models.SessionHolds{}
+	var current *models.SessionHolds
+	var price models.Money
+	for rows.Next() {
+		var seat models.HeldSeat
+		var eventID int
+		var currency string
+		if err := rows.Scan(&eventID, &seat.TicketID, &seat.SeatNo, &seat.HoldID, &seat.LockedUntil, &seat.SecondsRemaining,
+			&price, &currency); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan held seat: %w", err)
+		}
+		if current == nil || current.EventID != eventID {
+			current = &models.SessionHolds{EventID: eventID, Currency: currency, Seats: []*models.HeldSeat{}}
+			holds = append(holds, current)
+		}
+		current.Seats = append(current.Seats, &seat)
+		current.TotalPrice += price
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list session holds: %w", err)
+	}
+
+	return holds, total, nil
+}
+
+// ClearSeatLocks force-releases locked seats of an event, all of them when
+// seatNumbers is empty. Holds that lose a seat this way are expired, since they
+// can no longer be booked as a whole. actor and reason go to the audit log.
+func (r *EventRepository) ClearSeatLocks(ctx context.Context, eventID int, seatNumbers []string, actor string, reason string) (*models.LockClearSummary, error) {
+	summary := &models.LockClearSummary{EventID: eventID, SeatNumbers: []string{}}
+	var ticketIDs []int
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM events WHERE id = $1)`, eventID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check event: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("event not found")
+		}
+
+		var seatFilter interface{}
+		if len(seatNumbers) > 0 {
+			seatFilter = pq.Array(seatNumbers)
+		}
+
+		// Lock in seat order like every other multi-seat path, then release
+		clearQuery := `
+			WITH cleared AS (
+				SELECT id, seat_no, hold_id
+				FROM tickets
+				WHERE event_id = $1 AND status = 'locked' AND ($2::text[] IS NULL OR seat_no = ANY($2))
+				ORDER BY seat_no
+				FOR UPDATE
+			)
+			UPDATE tickets t
+			SET status = 'available', locked_by = NULL, hold_id = NULL, updated_at = NOW()
+			FROM cleared c
+			WHERE t.id = c.id
+			RETURNING c.id, c.seat_no, COALESCE(c.hold_id, '')`
+
+		rows, err := tx.QueryContext(ctx, clearQuery, eventID, seatFilter)
+		if err != nil {
+			return fmt.Errorf("failed to clear seat locks: %w", err)
+		}
+		var holdIDs []string
+		for rows.Next() {
+			var ticketID int
+			var seatNo, holdID string
+			if err := rows.Scan(&ticketID, &seatNo, &holdID); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to clear seat locks: %w", err)
+			}
+			ticketIDs = append(ticketIDs, ticketID)
+			summary.SeatNumbers = append(summary.SeatNumbers, seatNo)
+			if holdID != "" {
+				holdIDs = append(holdIDs, holdID)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to clear seat locks: %w", err)
+		}
+		summary.SeatsCleared = len(ticketIDs)
+
+		if len(holdIDs) > 0 {
+			expireQuery := `
+				UPDATE holds
+				SET status = 'expired', updated_at = NOW()
+				WHERE id = ANY($1) AND status = 'active'`
+
+			result, err := tx.ExecContext(ctx, expireQuery, pq.Array(holdIDs))
+			if err != nil {
+				return fmt.Errorf("failed to expire holds: %w", err)
+			}
+			expired, _ := result.RowsAffected()
+			summary.HoldsExpired = int(expired)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.lockExpiry.Cancel(ticketIDs...)
+
+	r.logger.WithFields(logrus.Fields{
+		"audit":         true,
+		"action":        "event.clear_locks",
+		"actor":         actor,
+		"event_id":      eventID,
+		"requested":     seatNumbers,
+		"seats_cleared": summary.SeatsCleared,
+		"seat_numbers":  summary.SeatNumbers,
+		"holds_expired": summary.HoldsExpired,
+		"reason":        reason,
+	}).Warn("Seat locks cleared manually")
+
+	return summary, nil
+}
+
+// SetSalesOpen pauses or resumes sales on an event and returns the new state.
+// A nil open flips the current state. Existing locks, holds and bookings are
+// left alone; only new ones are refused while sales are paused.
+func (r *EventRepository) SetSalesOpen(ctx context.Context, eventID int, open *bool, actor string) (bool, error) {
+	query := `
+		UPDATE events 
+		SET is_sales_open = COALESCE($2, NOT is_sales_open), updated_at = NOW() 
+		WHERE id = $1 
+		RETURNING is_sales_open`
+
+	var salesOpen bool
+	if err := r.db.QueryRowContext(ctx, query, eventID, open).Scan(&salesOpen); err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("event not found")
+		}
+		return false, fmt.Errorf("failed to update sales state: %w", err)
+	}
+
+	r.cache.Invalidate(ctx, eventID)
+
+	r.logger.WithFields(logrus.Fields{
+		"audit":         true,
+		"action":        "set_sales_open",
+		"actor":         actor,
+		"event_id":      eventID,
+		"is_sales_open": salesOpen,
+	}).Warn("Event sales state changed")
+
+	return salesOpen, nil
+}
+
+// checkEventExists returns "event not found" for a missing event
+func (r *EventRepository) checkEventExists(ctx context.Context, eventID int) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM events WHERE id = $1)`, eventID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check event: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("event not found")
+	}
+	return nil
+}
+
+// AdjustAvailability applies a manual signed delta to available_tickets. The
+// result must stay within 0..total_tickets; actor and reason go to the audit log.
+func (r *EventRepository) AdjustAvailability(ctx context.Context, eventID int, delta int, actor string, reason string) error {
+	var before, total int
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		lockQuery := `SELECT available_tickets, total_tickets FROM events WHERE id = $1 FOR UPDATE`
+		err := tx.QueryRowContext(ctx, lockQuery, eventID).Scan(&before, &total)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("event not found")
+			}
+			return fmt.Errorf("failed to lock event: %w", err)
+		}
+
+		after := before + delta
+		if after < 0 || after > total {
+			return fmt.Errorf("adjustment out of bounds: available tickets would be %d, must be between 0 and %d", after, total)
+		}
+
+		updateQuery := `UPDATE events SET available_tickets = $1, updated_at = NOW() WHERE id = $2`
+		if _, err := tx.ExecContext(ctx, updateQuery, after, eventID); err != nil {
+			return fmt.Errorf("failed to adjust available tickets: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	r.cache.Invalidate(ctx, eventID)
+
+	r.logger.WithFields(logrus.Fields{
+		"audit":    true,
+		"action":   "event.adjust_availability",
+		"actor":    actor,
+		"event_id": eventID,
+		"delta":    delta,
+		"before":   before,
+		"after":    before + delta,
+		"reason":   reason,
+	}).Warn("Available tickets adjusted manually")
+
+	return nil
+}
+
+// RebuildTickets recreates missing ticket rows of an event from its labelling
+// scheme and total_tickets, then recomputes available_tickets. Existing tickets
+// are never changed. When the existing tickets don't fit the scheme (unknown or
+// duplicated seats, e.g. an event created with explicit labels) nothing is
+// written and a *TicketRebuildConflictError says which seats are in the way.
+func (r *EventRepository) RebuildTickets(ctx context.Context, eventID int, actor string) (*models.TicketRebuild, error) {
+	rebuild := &models.TicketRebuild{EventID: eventID, SeatNumbers: []string{}}
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		// The event lock keeps bookings from moving the counter while the
+		// tickets are compared and refilled
+		var format string
+		var rows, total int
+		eventQuery := `
+			SELECT COALESCE(seat_label_format, ''), COALESCE(seat_rows, 0), total_tickets
+			FROM events WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, eventQuery, eventID).Scan(&format, &rows, &total); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("event not found")
+			}
+			return fmt.Errorf("failed to lock event: %w", err)
+		}
+
+		expected, err := seating.GenerateLabels(format, rows, total)
+		if err != nil {
+			return fmt.Errorf("failed to generate seat labels: %w", err)
+		}
+		inScheme := make(map[string]bool, len(expected))
+		for _, label := range expected {
+			inScheme[label] = true
+		}
+
+		ticketRows, err := tx.QueryContext(ctx, `SELECT seat_no, status FROM tickets WHERE event_id = $1 ORDER BY seat_no, id`, eventID)
+		if err != nil {
+			return fmt.Errorf("failed to list tickets: %w", err)
+		}
+		present := make(map[string]bool)
+		conflict := &TicketRebuildConflictError{EventID: eventID, Seats: []string{}, BookedSeats: []string{}}
+		for ticketRows.Next() {
+			var seatNo string
+			var status models.TicketStatus
+			if err := ticketRows.Scan(&seatNo, &status); err != nil {
+				ticketRows.Close()
+				return fmt.Errorf("failed to scan ticket: %w", err)
+			}
+			if !inScheme[seatNo] || present[seatNo] {
+				conflict.Seats = append(conflict.Seats, seatNo)
+				if status == models.TicketReserved || status == models.TicketSold {
+					conflict.BookedSeats = append(conflict.BookedSeats, seatNo)
+				}
+			}
+			present[seatNo] = true
+		}
+		ticketRows.Close()
+		if err := ticketRows.Err(); err != nil {
+			return fmt.Errorf("failed to list tickets: %w", err)
+		}
+		if len(conflict.Seats) > 0 {
+			return conflict
+		}
+
+		for _, label := range expected {
+			if !present[label] {
+				rebuild.SeatNumbers = append(rebuild.SeatNumbers, label)
+			}
+		}
+		rebuild.TicketsAdded = len(rebuild.SeatNumbers)
+		if rebuild.TicketsAdded == 0 {
+			return tx.QueryRowContext(ctx, `SELECT available_tickets FROM events WHERE id = $1`, eventID).Scan(&rebuild.AvailableTickets)
+		}
+
+		if err := insertTickets(ctx, tx, eventID, rebuild.SeatNumbers); err != nil {
+			return err
+		}
+
+		updateQuery := `
+			UPDATE events
+			SET available_tickets = (SELECT COUNT(*) FROM tickets WHERE event_id = $1 AND status IN ('available', 'locked')),
+				updated_at = NOW()
+			WHERE id = $1
+			RETURNING available_tickets`
+		if err := tx.QueryRowContext(ctx, updateQuery, eventID).Scan(&rebuild.AvailableTickets); err != nil {
+			return fmt.Errorf("failed to update available tickets: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if rebuild.TicketsAdded > 0 {
+		r.cache.Invalidate(ctx, eventID)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"audit":             true,
+		"action":            "event.rebuild_tickets",
+		"actor":             actor,
+		"event_id":          eventID,
+		"tickets_added":     rebuild.TicketsAdded,
+		"seat_numbers":      rebuild.SeatNumbers,
+		"available_tickets": rebuild.AvailableTickets,
+	}).Warn("Event tickets rebuilt")
+
+	return rebuild, nil
+}
+
+// ReconcileAvailability recomputes available_tickets from the tickets table and
+// corrects the events row if the counter has drifted. Locked seats are still
+// counted as available because the counter is only decremented on booking.
+func (r *EventRepository) ReconcileAvailability(ctx context.Context, eventID int) error {
+	corrected := false
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var recorded int
+		err := tx.QueryRowContext(ctx, `SELECT available_tickets FROM events WHERE id = $1 FOR UPDATE`, eventID).Scan(&recorded)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("event not found")
+			}
+			return fmt.Errorf("failed to lock event: %w", err)
+		}
+
+		var actual int
+		countQuery := `SELECT COUNT(*) FROM tickets WHERE event_id = $1 AND status IN ('available', 'locked')`
+		if err := tx.QueryRowContext(ctx, countQuery, eventID).Scan(&actual); err != nil {
+			return fmt.Errorf("failed to count available tickets: %w", err)
+		}
+
+		if actual == recorded {
+			return nil
+		}
+
+		updateQuery := `UPDATE events SET available_tickets = $1, updated_at = NOW() WHERE id = $2`
+		if _, err := tx.ExecContext(ctx, updateQuery, actual, eventID); err != nil {
+			return fmt.Errorf("failed to correct available tickets: %w", err)
+		}
+
+		r.logger.WithFields(logrus.Fields{
+			"event_id":    eventID,
+			"recorded":    recorded,
+			"actual":      actual,
+			"discrepancy": recorded - actual,
+		}).Warn("Corrected available_tickets drift")
+
+		corrected = true
+		return nil
+	})
+
+	if err == nil && corrected {
+		r.cache.Invalidate(ctx, eventID)
+	}
+	return err
+}
+
+// orphanGracePeriod keeps the orphan sweep away from tickets that were
+// reserved moments ago
+const orphanGracePeriod = time.Minute
+
+// ReleaseOrphanedReservations releases tickets stuck in 'reserved' with no
+// pending booking referencing them. Booking reserves seats and inserts the
+// booking in one transaction, so this should find nothing; it is a safety net
+// in case a future change splits those steps. Released seats are counted
+// back into available_tickets, since reserving took them out.
+func (r *EventRepository) ReleaseOrphanedReservations(ctx context.Context) error {
+	query := `
+		WITH orphaned AS (
+			SELECT t.id 
+			FROM tickets t 
+			WHERE t.status = 'reserved' 
+			AND t.updated_at < NOW() - make_interval(secs => $1) 
+			AND NOT EXISTS (
+				SELECT 1 FROM bookings b 
+				WHERE b.status = 'pending' AND t.id = ANY(b.ticket_ids)
+			)
+			ORDER BY t.id 
+			FOR UPDATE OF t SKIP LOCKED
+		), released AS (
+			UPDATE tickets t 
+			SET status = 'available', hold_id = NULL, locked_by = NULL, updated_at = NOW() 
+			FROM orphaned o 
+			WHERE t.id = o.id 
+			RETURNING t.event_id
+		), per_event AS (
+			SELECT event_id, COUNT(*) AS seats FROM released GROUP BY event_id
+		)
+		UPDATE events e 
+		SET available_tickets = LEAST(e.available_tickets + p.seats, e.total_tickets), updated_at = NOW() 
+		FROM per_event p 
+		WHERE e.id = p.event_id 
+		RETURNING e.id, p.seats`
+
+	rows, err := r.db.QueryContext(ctx, query, orphanGracePeriod.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to release orphaned reservations: %w", err)
+	}
+	defer rows.Close()
+
+	var eventIDs []int
+	var total int64
+	for rows.Next() {
+		var eventID int
+		var seats int64
+		if err := rows.Scan(&eventID, &seats); err != nil {
+			return fmt.Errorf("failed to scan released reservations: %w", err)
+		}
+		r.logger.WithFields(logrus.Fields{
+			"event_id":       eventID,
+			"seats_released": seats,
+		}).Warn("Released orphaned reservations")
+		eventIDs = append(eventIDs, eventID)
+		total += seats
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to release orphaned reservations: %w", err)
+	}
+
+	for _, eventID := range eventIDs {
+		r.cache.Invalidate(ctx, eventID)
+	}
+	if total > 0 {
+		r.logger.WithFields(logrus.Fields{
+			"events":         len(eventIDs),
+			"seats_released": total,
+		}).Warn("Orphaned reservation cleanup released seats")
+	}
+
+	return nil
+}
+
+// ReconcileAllAvailability runs ReconcileAvailability for every event
+func (r *EventRepository) ReconcileAllAvailability(ctx context.Context) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM events ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var eventIDs []int
+	for rows.Next() {
+		var eventID int
+		if err := rows.Scan(&eventID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan event id: %w", err)
+		}
+		eventIDs = append(eventIDs, eventID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list events: %w", err)
+	}
+
+	for _, eventID := range eventIDs {
+		if err := r.ReconcileAvailability(ctx, eventID); err != nil {
+			return fmt.Errorf("failed to reconcile event %d: %w", eventID, err)
+		}
+	}
+
+	return nil
+}
+
+// CheckInTicket admits a sold ticket at the gate, identified by ticket id
+func (r *EventRepository) CheckInTicket(ctx context.Context, ticketID int, actor string) (*models.CheckIn, error) {
+	query := `
+		SELECT t.id, t.event_id, t.seat_no, t.status, t.checked_in_at, COALESCE(t.checked_in_by, ''),
+			   COALESCE(b.booking_ref, ''), COALESCE(b.status, '')
+		FROM tickets t
+		LEFT JOIN LATERAL (
+			SELECT booking_ref, status 
+			FROM bookings 
+			WHERE t.id = ANY(ticket_ids) 
+			ORDER BY status = 'confirmed' DESC, id DESC 
+			LIMIT 1
+		) b ON TRUE
+		WHERE t.id = $1
+		FOR UPDATE OF t`
+
+	return r.checkIn(ctx, actor, query, ticketID)
+}
+
+// CheckInBySeat admits a sold ticket at the gate, identified by the booking
+// reference and seat printed on it
+func (r *EventRepository) CheckInBySeat(ctx context.Context, bookingRef, seatNo, actor string) (*models.CheckIn, error) {
+	query := `
+		SELECT t.id, t.event_id, t.seat_no, t.status, t.checked_in_at, COALESCE(t.checked_in_by, ''),
+			   b.booking_ref, b.status
+		FROM bookings b
+		JOIN tickets t ON t.id = ANY(b.ticket_ids)
+		WHERE b.booking_ref = $1 AND t.seat_no = $2
+		FOR UPDATE OF t`
+
+	return r.checkIn(ctx, actor, query, bookingRef, seatNo)
+}
+
+// checkIn locks the ticket selected by query and stamps it as checked in. Only
+// sold tickets of confirmed bookings are admitted, and only once.
+func (r *EventRepository) checkIn(ctx context.Context, actor string, query string, args ...interface{}) (*models.CheckIn, error) {
+	var checkIn models.CheckIn
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var status models.TicketStatus
+		var bookingStatus string
+		var checkedInAt sql.NullTime
+		var checkedInBy string
+
+		err := tx.QueryRowContext(ctx, query, args...).Scan(
+			&checkIn.TicketID,
+			&checkIn.EventID,
+			&checkIn.SeatNo,
+			&status,
+			&checkedInAt,
+			&checkedInBy,
+			&checkIn.BookingRef,
+			&bookingStatus,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("ticket not found")
+			}
+			return fmt.Errorf("failed to lock ticket: %w", err)
+		}
+
+		if checkedInAt.Valid {
+			return &AlreadyCheckedInError{
+				TicketID:    checkIn.TicketID,
+				CheckedInAt: checkedInAt.Time,
+				CheckedInBy: checkedInBy,
+			}
+		}
+		if status != models.TicketSold {
+			return fmt.Errorf("ticket %d is not sold (current status: %s)", checkIn.TicketID, status)
+		}
+		if bookingStatus != string(models.BookingConfirmed) {
+			return fmt.Errorf("ticket %d is not sold under booking %s (booking status: %s)", checkIn.TicketID, checkIn.BookingRef, bookingStatus)
+		}
+
+		updateQuery := `
+			UPDATE tickets 
+			SET checked_in_at = NOW(), checked_in_by = $2, updated_at = NOW() 
+			WHERE id = $1 
+			RETURNING checked_in_at`
+
+		if err := tx.QueryRowContext(ctx, updateQuery, checkIn.TicketID, actor).Scan(&checkIn.CheckedInAt); err != nil {
+			return fmt.Errorf("failed to check in ticket: %w", err)
+		}
+		checkIn.CheckedInBy = actor
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"audit":       true,
+		"action":      "check_in",
+		"actor":       actor,
+		"ticket_id":   checkIn.TicketID,
+		"event_id":    checkIn.EventID,
+		"seat_no":     checkIn.SeatNo,
+		"booking_ref": checkIn.BookingRef,
+	}).Info("Ticket checked in")
+
+	return &checkIn, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// TestPostgresEventNullText reads an event whose description and venue are
+// NULL, as a row inserted outside the app can be, and expects empty strings
+func TestPostgresEventNullText(t *testing.T) {
+	_, eventRepo := testRepos(t)
+	database := eventRepo.db
+	ctx := context.Background()
+
+	// venue is NOT NULL in our schema, but not in every database we read from
+	if _, err := database.ExecContext(ctx, `ALTER TABLE events ALTER COLUMN venue DROP NOT NULL`); err != nil {
+		t.Fatalf("drop NOT NULL on venue: %v", err)
+	}
+	var eventID int
+	t.Cleanup(func() {
+		database.ExecContext(ctx, `DELETE FROM events WHERE id = $1`, eventID)
+		if _, err := database.ExecContext(ctx, `ALTER TABLE events ALTER COLUMN venue SET NOT NULL`); err != nil {
+			t.Errorf("restore NOT NULL on venue: %v", err)
+		}
+	})
+
+	createdAfter := time.Now().Add(-time.Second)
+	start := time.Now().Add(24 * time.Hour)
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO events (name, description, venue, start_time, end_time, total_tickets, available_tickets, price)
+		VALUES ($1, NULL, NULL, $2, $3, 1, 1, 0)
+		RETURNING id`, t.Name(), start, start.Add(time.Hour)).Scan(&eventID)
+	if err != nil {
+		t.Fatalf("insert event: %v", err)
+	}
+
+	assertEmptyText := func(source string, event *models.Event) {
+		t.Helper()
+		if event.Description != "" || event.Venue != "" {
+			t.Errorf("%s: description = %q, venue = %q, want empty", source, event.Description, event.Venue)
+		}
+		body, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("%s: marshal event: %v", source, err)
+		}
+		for _, field := range []string{`"description":""`, `"venue":""`} {
+			if !strings.Contains(string(body), field) {
+				t.Errorf("%s: JSON %s does not contain %s", source, body, field)
+			}
+		}
+	}
+
+	event, err := eventRepo.GetEvent(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	assertEmptyText("GetEvent", event)
+
+	events, err := eventRepo.GetEvents(ctx, models.EventFilter{CreatedAfter: &createdAfter}, 100, 0)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	found := false
+	for _, e := range events {
+		if e.ID == eventID {
+			found = true
+			assertEmptyText("GetEvents", e)
+		}
+	}
+	if !found {
+		t.Errorf("GetEvents did not return event %d", eventID)
+	}
+}
+
+// TestPostgresGetEventFromPrimary checks the primary read sees a write made
+// straight to the database and still reports unknown events as not found
+func TestPostgresGetEventFromPrimary(t *testing.T) {
+	_, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 3, 2500)
+	ctx := context.Background()
+
+	if _, err := eventRepo.db.ExecContext(ctx, `UPDATE events SET available_tickets = 1 WHERE id = $1`, event.ID); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+
+	fresh, err := eventRepo.GetEventFromPrimary(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("GetEventFromPrimary: %v", err)
+	}
+	if fresh.AvailableTickets != 1 {
+		t.Errorf("available_tickets = %d, want 1", fresh.AvailableTickets)
+	}
+
+	if _, err := eventRepo.GetEventFromPrimary(ctx, -1); err == nil || err.Error() != "event not found" {
+		t.Errorf("GetEventFromPrimary(-1) = %v, want event not found", err)
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// TestPostgresFreeEventConfirmedAtomically books a free event and expects a
+// confirmed booking with sold tickets from the one transaction
+func TestPostgresFreeEventConfirmedAtomically(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 5, 0)
+	ctx := context.Background()
+
+	booking, err := bookingRepo.BookTickets(ctx, guestRequest(event.ID, 2, models.BookingModeAuto, 0))
+	if err != nil {
+		t.Fatalf("book free event: %v", err)
+	}
+
+	if booking.Status != models.BookingConfirmed || booking.PaymentRequired {
+		t.Errorf("booking status = %s, payment_required = %v, want confirmed without payment",
+			booking.Status, booking.PaymentRequired)
+	}
+	if booking.TotalAmount != 0 {
+		t.Errorf("total = %d, want 0", booking.TotalAmount)
+	}
+
+	stored, err := bookingRepo.GetBooking(ctx, booking.ID)
+	if err != nil {
+		t.Fatalf("read booking: %v", err)
+	}
+	if stored.Status != models.BookingConfirmed || stored.PaymentRequired {
+		t.Errorf("stored booking status = %s, payment_required = %v, want confirmed", stored.Status, stored.PaymentRequired)
+	}
+	if stored.ExpiresAt.After(stored.CreatedAt) {
+		t.Errorf("expires_at %s is after created_at %s, want no payment window", stored.ExpiresAt, stored.CreatedAt)
+	}
+
+	counts := ticketCounts(t, bookingRepo.db, event.ID)
+	if counts[models.TicketSold] != 2 || counts[models.TicketReserved] != 0 || counts[models.TicketAvailable] != 3 {
+		t.Errorf("ticket statuses = %v, want 2 sold and 3 available", counts)
+	}
+	if available := availableCounter(t, bookingRepo.db, event.ID); available != 3 {
+		t.Errorf("available_tickets = %d, want 3", available)
+	}
+}
+
+// TestPostgresFreeEventFailureSellsNothing fails a free booking after its
+// tickets were marked sold, and expects the whole booking rolled back
+func TestPostgresFreeEventFailureSellsNothing(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 3, 0)
+	ctx := context.Background()
+
+	// The counter is behind the tickets, so the decrement after the tickets
+	// are sold finds too few and the booking fails
+	if _, err := bookingRepo.db.ExecContext(ctx, `UPDATE events SET available_tickets = 1 WHERE id = $1`, event.ID); err != nil {
+		t.Fatalf("set counter: %v", err)
+	}
+
+	if _, err := bookDirect(ctx, bookingRepo, guestRequest(event.ID, 2, models.BookingModeAuto, 0)); err == nil {
+		t.Fatal("booking succeeded against a counter of 1")
+	}
+
+	if counts := ticketCounts(t, bookingRepo.db, event.ID); counts[models.TicketAvailable] != event.TotalTickets {
+		t.Errorf("ticket statuses = %v, want all %d still available", counts, event.TotalTickets)
+	}
+	var bookings int
+	if err := bookingRepo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM bookings WHERE event_id = $1`, event.ID).Scan(&bookings); err != nil {
+		t.Fatalf("count bookings: %v", err)
+	}
+	if bookings != 0 {
+		t.Errorf("%d bookings were written, want none", bookings)
+	}
+}
+
+// TestPostgresFreeEventWithServiceFee books a free event while a per-ticket
+// fee is configured; the fee does not apply and the booking is confirmed
+func TestPostgresFreeEventWithServiceFee(t *testing.T) {
+	database := testDB(t)
+	cfg := testConfig()
+	cfg.App.ServiceFee = models.ServiceFee{PerTicket: 150}
+	bookingRepo := NewBookingRepository(database, nil, nil, testLogger(), cfg)
+	eventRepo := NewEventRepository(database, nil, nil, testLogger(), cfg)
+	event := createTestEvent(t, eventRepo, 2, 0)
+
+	booking, err := bookingRepo.BookTickets(context.Background(), guestRequest(event.ID, 2, models.BookingModeAuto, 0))
+	if err != nil {
+		t.Fatalf("book free event: %v", err)
+	}
+	if booking.Status != models.BookingConfirmed || booking.TotalAmount != 0 || booking.Fees != 0 {
+		t.Errorf("booking status = %s, total = %d, fees = %d, want confirmed at 0",
+			booking.Status, booking.TotalAmount, booking.Fees)
+	}
+}
+
+// TestPostgresFullDiscountStaysPending books a paid event with a coupon that
+// covers it in full; the booking is free but still waits to be confirmed
+func TestPostgresFullDiscountStaysPending(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 2, 2500)
+	ctx := context.Background()
+
+	code := fmt.Sprintf("FREE%d", event.ID)
+	if _, err := bookingRepo.db.ExecContext(ctx,
+		`INSERT INTO coupons (code, percent_off, event_id) VALUES ($1, 100, $2)`, code, event.ID); err != nil {
+		t.Fatalf("create coupon: %v", err)
+	}
+
+	request := guestRequest(event.ID, 1, models.BookingModeAuto, 0)
+	request.CouponCode = code
+	booking, err := bookingRepo.BookTickets(ctx, request)
+	if err != nil {
+		t.Fatalf("book: %v", err)
+	}
+
+	if booking.TotalAmount != 0 {
+		t.Errorf("total = %d, want 0", booking.TotalAmount)
+	}
+	if booking.Status != models.BookingPending || !booking.PaymentRequired {
+		t.Errorf("booking status = %s, payment_required = %v, want pending", booking.Status, booking.PaymentRequired)
+	}
+	if counts := ticketCounts(t, bookingRepo.db, event.ID); counts[models.TicketReserved] != 1 || counts[models.TicketSold] != 0 {
+		t.Errorf("ticket statuses = %v, want 1 reserved and none sold", counts)
+	}
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/db"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+type HoldRepository struct {
+	db     *db.DB
+	logger *logrus.Logger
+	config *config.Config
+}
+
+func NewHoldRepository(database *db.DB, logger *logrus.Logger, cfg *config.Config) *HoldRepository {
+	return &HoldRepository{
+		db:     database,
+		logger: logger,
+		config: cfg,
+	}
+}
+
+// CreateHold locks the requested seats for a session and returns a hold token
+// that BookTickets can later convert into a booking
+func (r *HoldRepository) CreateHold(ctx context.Context, eventID int, seatNumbers []string, sessionID string) (*models.Hold, error) {
+	var hold *models.Hold
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var eventEnd, now time.Time
+		var salesOpen bool
+		err := tx.QueryRowContext(ctx, `SELECT end_time, is_sales_open, NOW() FROM events WHERE id = $1`, eventID).Scan(&eventEnd, &salesOpen, &now)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("event not found")
+			}
+			return fmt.Errorf("failed to check event: %w", err)
+		}
+		if !now.Before(eventEnd) {
+			return fmt.Errorf("event has already ended")
+		}
+		if !salesOpen {
+			return &SalesPausedError{EventID: eventID}
+		}
+
+		if r.config.App.MaxLocksPerSession > 0 {
+			if err := serializeSession(ctx, tx, eventID, sessionID); err != nil {
+				return err
+			}
+			if err := checkSessionLockLimit(ctx, tx, eventID, sessionID, len(seatNumbers), r.config.App.MaxLocksPerSession, r.config.App.SeatLockDuration); err != nil {
+				return err
+			}
+		}
+
+		// Lock the requested seats in a stable order to avoid deadlocks between holds
+		ticketQuery := `
+			SELECT id, seat_no, status
+			FROM tickets
+			WHERE event_id = $1 AND seat_no = ANY($2)
+			ORDER BY seat_no
+			FOR UPDATE`
+
+		rows, err := tx.QueryContext(ctx, ticketQuery, eventID, pq.Array(seatNumbers))
+		if err != nil {
+			return fmt.Errorf("failed to select seats: %w", err)
+		}
+		defer rows.Close()
+
+		var ticketIDs []int
+		var lockedSeats []string
+		for rows.Next() {
+			var ticketID int
+			var seatNo, status string
+			if err := rows.Scan(&ticketID, &seatNo, &status); err != nil {
+				return fmt.Errorf("failed to scan seat: %w", err)
+			}
+			if status != string(models.TicketAvailable) {
+				return fmt.Errorf("seat %s is no longer available (current status: %s)", seatNo, status)
+			}
+			ticketIDs = append(ticketIDs, ticketID)
+			lockedSeats = append(lockedSeats, seatNo)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read seats: %w", err)
+		}
+
+		if len(ticketIDs) != len(seatNumbers) {
+			return fmt.Errorf("seat not found: requested %d seats, found %d", len(seatNumbers), len(ticketIDs))
+		}
+
+		holdID, err := generateHoldID()
+		if err != nil {
+			return err
+		}
+		expiresAt := now.Add(r.config.App.SeatLockDuration)
+
+		insertHoldQuery := `
+			INSERT INTO holds (id, event_id, session_id, status, expires_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+			RETURNING created_at`
+
+		var createdAt time.Time
+		err = tx.QueryRowContext(ctx, insertHoldQuery, holdID, eventID, sessionID, models.HoldActive, expiresAt).Scan(&createdAt)
+		if err != nil {
+			return fmt.Errorf("failed to create hold: %w", err)
+		}
+
+		lockQuery := `
+			UPDATE tickets
+			SET status = 'locked', hold_id = $1, locked_by = $3, updated_at = NOW()
+			WHERE id = ANY($2)`
+
+		if _, err := tx.ExecContext(ctx, lockQuery, holdID, pq.Array(ticketIDs), sessionID); err != nil {
+			return fmt.Errorf("failed to lock held seats: %w", err)
+		}
+
+		hold = &models.Hold{
+			ID:          holdID,
+			EventID:     eventID,
+			SessionID:   sessionID,
+			TicketIDs:   ticketIDs,
+			SeatNumbers: lockedSeats,
+			Status:      models.HoldActive,
+			ExpiresAt:   expiresAt,
+			CreatedAt:   createdAt,
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"hold_id":    hold.ID,
+		"event_id":   eventID,
+		"session":    sessionID,
+		"seats":      hold.SeatNumbers,
+		"expires_at": hold.ExpiresAt,
+	}).Info("Seats held")
+
+	return hold, nil
+}
+
+// CleanupExpiredHolds expires active holds past their expiry and releases their seats
+func (r *HoldRepository) CleanupExpiredHolds(ctx context.Context) error {
+	var seatsReleased, holdsExpired int64
+
+	err := r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		releaseQuery := `
+			UPDATE tickets t
+			SET status = 'available', hold_id = NULL, locked_by = NULL, updated_at = NOW()
+			FROM holds h
+			WHERE t.hold_id = h.id
+			AND t.status = 'locked'
+			AND h.status = 'active'
+			AND h.expires_at < NOW()`
+
+		result, err := tx.ExecContext(ctx, releaseQuery)
+		if err != nil {
+			return fmt.Errorf("failed to release held seats: %w", err)
+		}
+		seatsReleased, _ = result.RowsAffected()
+
+		expireQuery := `
+			UPDATE holds
+			SET status = 'expired', updated_at = NOW()
+			WHERE status = 'active' AND expires_at < NOW()`
+
+		result, err = tx.ExecContext(ctx, expireQuery)
+		if err != nil {
+			return fmt.Errorf("failed to expire holds: %w", err)
+		}
+		holdsExpired, _ = result.RowsAffected()
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired holds: %w", err)
+	}
+
+	if holdsExpired > 0 {
+		r.logger.WithFields(logrus.Fields{
+			"holds_expired":  holdsExpired,
+			"seats_released": seatsReleased,
+		}).Info("Cleaned up expired holds")
+	}
+
+	return nil
+}
+
+func generateHoldID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate hold id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
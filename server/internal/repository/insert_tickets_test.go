@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/seating"
+)
+
+const benchmarkSeats = 10000
+
+// withEventTx runs fn in a transaction holding a new event row, and rolls it
+// all back afterwards so benchmark iterations leave nothing behind
+func withEventTx(tb testing.TB, fn func(tx *sql.Tx, eventID int)) {
+	tb.Helper()
+	ctx := context.Background()
+
+	tx, err := testDB(tb).BeginTx(ctx, nil)
+	if err != nil {
+		tb.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	start := time.Now().Add(24 * time.Hour)
+	var eventID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO events (name, venue, start_time, end_time, total_tickets, available_tickets, price)
+		VALUES ($1, 'Bench Arena', $2, $3, $4, $4, 0)
+		RETURNING id`, tb.Name(), start, start.Add(time.Hour), benchmarkSeats).Scan(&eventID)
+	if err != nil {
+		tb.Fatalf("insert event: %v", err)
+	}
+
+	fn(tx, eventID)
+}
+
+func benchmarkLabels(b *testing.B) []string {
+	labels, err := seating.GenerateLabels("", 0, benchmarkSeats)
+	if err != nil {
+		b.Fatalf("generate labels: %v", err)
+	}
+	return labels
+}
+
+// BenchmarkInsertTickets creates the tickets of a 10,000-seat event the way
+// CreateEvent does, in batches of ticketInsertBatchSize
+func BenchmarkInsertTickets(b *testing.B) {
+	testDB(b)
+	labels := benchmarkLabels(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		withEventTx(b, func(tx *sql.Tx, eventID int) {
+			if err := insertTickets(ctx, tx, eventID, labels); err != nil {
+				b.Fatalf("insert tickets: %v", err)
+			}
+		})
+	}
+}
+
+// BenchmarkInsertTicketsPerRow is the baseline insertTickets replaced: one
+// INSERT per seat
+func BenchmarkInsertTicketsPerRow(b *testing.B) {
+	testDB(b)
+	labels := benchmarkLabels(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		withEventTx(b, func(tx *sql.Tx, eventID int) {
+			for _, label := range labels {
+				_, err := tx.ExecContext(ctx, `
+					INSERT INTO tickets (event_id, seat_no, status, created_at, updated_at)
+					VALUES ($1, $2, 'available', NOW(), NOW())`, eventID, label)
+				if err != nil {
+					b.Fatalf("insert ticket %s: %v", label, err)
+				}
+			}
+		})
+	}
+}
+
+// TestPostgresInsertTicketsKeepsLabels checks batching writes every label
+// once and in order, across more than one batch
+func TestPostgresInsertTicketsKeepsLabels(t *testing.T) {
+	labels, err := seating.GenerateLabels("", 0, ticketInsertBatchSize+7)
+	if err != nil {
+		t.Fatalf("generate labels: %v", err)
+	}
+	ctx := context.Background()
+
+	withEventTx(t, func(tx *sql.Tx, eventID int) {
+		if err := insertTickets(ctx, tx, eventID, labels); err != nil {
+			t.Fatalf("insert tickets: %v", err)
+		}
+
+		rows, err := tx.QueryContext(ctx, `SELECT seat_no FROM tickets WHERE event_id = $1 ORDER BY id`, eventID)
+		if err != nil {
+			t.Fatalf("list tickets: %v", err)
+		}
+		defer rows.Close()
+
+		var got []string
+		for rows.Next() {
+			var seatNo string
+			if err := rows.Scan(&seatNo); err != nil {
+				t.Fatalf("scan ticket: %v", err)
+			}
+			got = append(got, seatNo)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatalf("list tickets: %v", err)
+		}
+
+		if len(got) != len(labels) {
+			t.Fatalf("inserted %d tickets, want %d", len(got), len(labels))
+		}
+		for i := range labels {
+			if got[i] != labels[i] {
+				t.Fatalf("ticket %d is %q, want %q", i, got[i], labels[i])
+			}
+		}
+	})
+}
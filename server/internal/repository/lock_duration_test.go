@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// lockedSeatRepo returns an event repository running with cfg and a one-seat
+// event whose seat session has locked
+func lockedSeatRepo(t *testing.T, cfg *config.Config, session string) (*EventRepository, *models.Event, *models.Ticket) {
+	t.Helper()
+	eventRepo := NewEventRepository(testDB(t), nil, nil, testLogger(), cfg)
+	event := createTestEvent(t, eventRepo, 1, 2500)
+	ctx := context.Background()
+
+	tickets, _, err := eventRepo.GetAllTickets(ctx, event.ID, "", event.TotalTickets)
+	if err != nil {
+		t.Fatalf("list tickets: %v", err)
+	}
+	if err := eventRepo.LockSeat(ctx, event.ID, tickets[0].SeatNo, session); err != nil {
+		t.Fatalf("lock seat: %v", err)
+	}
+	return eventRepo, event, tickets[0]
+}
+
+// fractionalLockConfig has a default seat lock duration with a fractional
+// second, and a session limit so LockSeat counts locks against it
+func fractionalLockConfig() *config.Config {
+	cfg := testConfig()
+	cfg.App.SeatLockDuration = 90500 * time.Millisecond
+	cfg.App.MaxLocksPerSession = 4
+	return cfg
+}
+
+// backdateLock moves an event's lock timestamps back by age
+func backdateLock(t *testing.T, eventRepo *EventRepository, eventID int, age time.Duration) {
+	t.Helper()
+	_, err := eventRepo.db.ExecContext(context.Background(), `
+		UPDATE tickets SET updated_at = updated_at - make_interval(secs => $2), locked_at = locked_at - make_interval(secs => $2)
+		WHERE event_id = $1`, eventID, age.Seconds())
+	if err != nil {
+		t.Fatalf("backdate lock: %v", err)
+	}
+}
+
+// TestPostgresFractionalSeatLockDuration runs the lock expiry queries with a
+// SEAT_LOCK_DURATION that is not a whole number of seconds
+func TestPostgresFractionalSeatLockDuration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cleanup", func(t *testing.T) {
+		eventRepo, event, _ := lockedSeatRepo(t, fractionalLockConfig(), "session-cleanup")
+
+		if err := eventRepo.CleanupExpiredLocks(ctx); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+		if counts := ticketCounts(t, eventRepo.db, event.ID); counts[models.TicketLocked] != 1 {
+			t.Fatalf("ticket statuses = %v after cleanup of a fresh lock, want 1 locked", counts)
+		}
+
+		backdateLock(t, eventRepo, event.ID, 2*time.Minute)
+		if err := eventRepo.CleanupExpiredLocks(ctx); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+		if counts := ticketCounts(t, eventRepo.db, event.ID); counts[models.TicketAvailable] != 1 {
+			t.Errorf("ticket statuses = %v after cleanup of an expired lock, want 1 available", counts)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		eventRepo, event, _ := lockedSeatRepo(t, fractionalLockConfig(), "session-list")
+
+		locks, err := eventRepo.GetSeatLocks(ctx, event.ID)
+		if err != nil {
+			t.Fatalf("list locks: %v", err)
+		}
+		if len(locks) != 1 || locks[0].SessionID != "session-list" || locks[0].Overdue {
+			t.Errorf("locks = %+v, want one live lock by session-list", locks)
+		}
+	})
+
+	t.Run("session holds", func(t *testing.T) {
+		eventRepo, event, _ := lockedSeatRepo(t, fractionalLockConfig(), "session-holds")
+
+		holds, err := eventRepo.GetSessionHolds(ctx, event.ID, "session-holds")
+		if err != nil {
+			t.Fatalf("session holds: %v", err)
+		}
+		if len(holds.Seats) != 1 || holds.Seats[0].SecondsRemaining <= 0 {
+			t.Errorf("held seats = %+v, want one with time remaining", holds.Seats)
+		}
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		eventRepo, event, ticket := lockedSeatRepo(t, fractionalLockConfig(), "session-expire")
+
+		backdateLock(t, eventRepo, event.ID, 2*time.Minute)
+		if err := eventRepo.ExpireSeatLock(ctx, ticket.ID, "session-expire"); err != nil {
+			t.Fatalf("expire: %v", err)
+		}
+		if counts := ticketCounts(t, eventRepo.db, event.ID); counts[models.TicketAvailable] != 1 {
+			t.Errorf("ticket statuses = %v after expiring the lock, want 1 available", counts)
+		}
+	})
+}
+
+// TestPostgresFractionalMaxHoldDuration cleans up with MAX_HOLD_DURATION=90.5s:
+// a lock refreshed recently but first taken longer ago than that is released
+func TestPostgresFractionalMaxHoldDuration(t *testing.T) {
+	cfg := testConfig()
+	cfg.App.MaxHoldDuration = 90500 * time.Millisecond
+	eventRepo, event, _ := lockedSeatRepo(t, cfg, "session-hold")
+	ctx := context.Background()
+
+	if err := eventRepo.CleanupExpiredLocks(ctx); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if counts := ticketCounts(t, eventRepo.db, event.ID); counts[models.TicketLocked] != 1 {
+		t.Fatalf("ticket statuses = %v after cleanup of a fresh lock, want 1 locked", counts)
+	}
+
+	// Only locked_at is old, as if the session kept refreshing the lock
+	if _, err := eventRepo.db.ExecContext(ctx,
+		`UPDATE tickets SET locked_at = locked_at - INTERVAL '2 minutes' WHERE event_id = $1`, event.ID); err != nil {
+		t.Fatalf("backdate locked_at: %v", err)
+	}
+	if err := eventRepo.CleanupExpiredLocks(ctx); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if counts := ticketCounts(t, eventRepo.db, event.ID); counts[models.TicketAvailable] != 1 {
+		t.Errorf("ticket statuses = %v after the maximum hold passed, want 1 available", counts)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/milinddethe15/ticket-booking/migrations"
+)
+
+var errRollback = errors.New("rollback")
+
+// TestPostgresMigrationsRerun applies every up migration again to the migrated
+// test database, as the first RUN_MIGRATIONS run does on a database set up by
+// init-db.sh before schema_migrations existed. The changes are rolled back.
+func TestPostgresMigrationsRerun(t *testing.T) {
+	database := testDB(t)
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		t.Fatalf("list migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	err = database.WithTransaction(ctx, func(tx *sql.Tx) error {
+		for _, file := range files {
+			script, err := fs.ReadFile(migrations.FS, file)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("re-apply migrations: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// TestPostgresModifyBookingSalesPaused pauses sales between booking and
+// modifying; adding seats must be refused, giving seats back must not
+func TestPostgresModifyBookingSalesPaused(t *testing.T) {
+	bookingRepo, eventRepo := testRepos(t)
+	event := createTestEvent(t, eventRepo, 5, 2500)
+	ctx := context.Background()
+
+	booking, err := bookDirect(ctx, bookingRepo, guestRequest(event.ID, 2, models.BookingModeAuto, 0))
+	if err != nil {
+		t.Fatalf("book: %v", err)
+	}
+
+	closed := false
+	if _, err := eventRepo.SetSalesOpen(ctx, event.ID, &closed, "test"); err != nil {
+		t.Fatalf("pause sales: %v", err)
+	}
+
+	_, err = bookingRepo.ModifyBooking(ctx, booking.ID, 3)
+	var pausedErr *SalesPausedError
+	if !errors.As(err, &pausedErr) || pausedErr.EventID != event.ID {
+		t.Fatalf("adding a seat while paused = %v, want a SalesPausedError", err)
+	}
+	if available := availableCounter(t, bookingRepo.db, event.ID); available != 3 {
+		t.Errorf("available_tickets = %d after a refused modification, want 3", available)
+	}
+
+	modified, err := bookingRepo.ModifyBooking(ctx, booking.ID, 1)
+	if err != nil {
+		t.Fatalf("removing a seat while paused: %v", err)
+	}
+	if modified.Quantity != 1 {
+		t.Errorf("quantity = %d, want 1", modified.Quantity)
+	}
+	if available := availableCounter(t, bookingRepo.db, event.ID); available != 4 {
+		t.Errorf("available_tickets = %d, want 4", available)
+	}
+}
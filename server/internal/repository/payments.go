@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// PaymentVerifier checks a payment reference with the payment gateway before a
+// booking is confirmed against it. It is called inside the confirming
+// transaction with the booking row locked, so it should answer promptly.
+type PaymentVerifier interface {
+	// VerifyPayment returns nil when ref is a completed payment of the
+	// booking's TotalAmount in its Currency, and an error saying why not otherwise
+	VerifyPayment(ctx context.Context, booking *models.Booking, ref string) error
+}
+
+// verifyPayment checks paymentRef for booking. Without a verifier configured
+// nothing can vouch for the reference, so it is refused rather than trusted.
+func (r *BookingRepository) verifyPayment(ctx context.Context, booking *models.Booking, paymentRef string) error {
+	if r.payments == nil {
+		return &PaymentVerifierUnavailableError{BookingID: booking.ID}
+	}
+
+	if err := r.payments.VerifyPayment(ctx, booking, paymentRef); err != nil {
+		// A request that gave up is not a verdict on the payment
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"booking_id":  booking.ID,
+			"payment_ref": paymentRef,
+		}).Warn("Payment verification failed")
+		return &PaymentVerificationError{BookingID: booking.ID, PaymentRef: paymentRef, Reason: err.Error()}
+	}
+	return nil
+}
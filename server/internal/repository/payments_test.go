@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// stubVerifier accepts only the payment reference it was given
+type stubVerifier struct {
+	accept string
+	calls  int
+	amount models.Money
+}
+
+func (v *stubVerifier) VerifyPayment(ctx context.Context, booking *models.Booking, ref string) error {
+	v.calls++
+	v.amount = booking.TotalAmount
+	if ref != v.accept {
+		return errors.New("payment not found")
+	}
+	return nil
+}
+
+// pendingBooking books one seat of a new paid event with payments as verifier
+func pendingBooking(t *testing.T, payments PaymentVerifier) (*BookingRepository, *models.Booking) {
+	t.Helper()
+	database := testDB(t)
+	bookingRepo := NewBookingRepository(database, nil, payments, testLogger(), testConfig())
+	eventRepo := NewEventRepository(database, nil, nil, testLogger(), testConfig())
+	event := createTestEvent(t, eventRepo, 2, 2500)
+
+	booking, err := bookDirect(context.Background(), bookingRepo, guestRequest(event.ID, 1, models.BookingModeAuto, 0))
+	if err != nil {
+		t.Fatalf("book: %v", err)
+	}
+	return bookingRepo, booking
+}
+
+func assertBookingStatus(t *testing.T, bookingRepo *BookingRepository, bookingID int, want models.BookingStatus) {
+	t.Helper()
+	booking, err := bookingRepo.GetBooking(context.Background(), bookingID)
+	if err != nil {
+		t.Fatalf("read booking: %v", err)
+	}
+	if booking.Status != want {
+		t.Errorf("booking status = %s, want %s", booking.Status, want)
+	}
+}
+
+func TestPostgresConfirmVerifiesPayment(t *testing.T) {
+	verifier := &stubVerifier{accept: "pay_ok"}
+	bookingRepo, booking := pendingBooking(t, verifier)
+	ctx := context.Background()
+
+	err := bookingRepo.ConfirmBooking(ctx, booking.ID, "pay_forged")
+	var verifyErr *PaymentVerificationError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("confirm with a bad reference = %v, want a PaymentVerificationError", err)
+	}
+	assertBookingStatus(t, bookingRepo, booking.ID, models.BookingPending)
+
+	if err := bookingRepo.ConfirmBooking(ctx, booking.ID, "pay_ok"); err != nil {
+		t.Fatalf("confirm with a good reference: %v", err)
+	}
+	assertBookingStatus(t, bookingRepo, booking.ID, models.BookingConfirmed)
+
+	if verifier.calls != 2 || verifier.amount != booking.TotalAmount {
+		t.Errorf("verifier called %d times for %d, want 2 for %d", verifier.calls, verifier.amount, booking.TotalAmount)
+	}
+}
+
+func TestPostgresConfirmWithoutVerifierFailsClosed(t *testing.T) {
+	bookingRepo, booking := pendingBooking(t, nil)
+
+	err := bookingRepo.ConfirmBooking(context.Background(), booking.ID, "pay_anything")
+	var unavailableErr *PaymentVerifierUnavailableError
+	if !errors.As(err, &unavailableErr) {
+		t.Fatalf("confirm with a reference and no verifier = %v, want a PaymentVerifierUnavailableError", err)
+	}
+	assertBookingStatus(t, bookingRepo, booking.ID, models.BookingPending)
+}
+
+func TestPostgresConfirmWithoutPaymentRef(t *testing.T) {
+	verifier := &stubVerifier{accept: "pay_ok"}
+	bookingRepo, booking := pendingBooking(t, verifier)
+
+	// Manual settlement confirms without a reference and skips the gateway
+	if err := bookingRepo.ConfirmBooking(context.Background(), booking.ID, ""); err != nil {
+		t.Fatalf("confirm without a reference: %v", err)
+	}
+	assertBookingStatus(t, bookingRepo, booking.ID, models.BookingConfirmed)
+	if verifier.calls != 0 {
+		t.Errorf("verifier called %d times for a confirm without a reference", verifier.calls)
+	}
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// priceBooking works out what quantity seats at unitPrice cost, less the
+// coupon if there is one, plus the configured service fee and tax. Booking,
+// modifying a booking and quoting all price through it, so a quote always
+// matches the booking that follows.
+func priceBooking(unitPrice models.Money, quantity int, coupon *models.Coupon, currency string, app *config.AppConfig) (*models.PriceBreakdown, error) {
+	// Money is in minor units and rates in parts per million, so every step
+	// is exact integer math with one rounding per charge
+	subtotal := unitPrice.Mul(quantity)
+	if subtotal < 0 {
+		return nil, fmt.Errorf("invalid booking total: %s", subtotal)
+	}
+
+	price := &models.PriceBreakdown{Subtotal: subtotal}
+	if coupon != nil {
+		discount, err := couponDiscount(coupon, subtotal, currency)
+		if err != nil {
+			return nil, err
+		}
+		price.Discount = discount
+	}
+
+	// Free tickets, including those a coupon covers in full, carry no fee
+	tickets := subtotal - price.Discount
+	if tickets > 0 {
+		price.Fees = app.ServiceFee.For(tickets, quantity)
+	}
+	price.Tax = (tickets + price.Fees).ApplyRate(app.TaxRate)
+	price.Total = tickets + price.Fees + price.Tax
+	return price, nil
+}
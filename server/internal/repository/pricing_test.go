@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+func TestPriceBooking(t *testing.T) {
+	percent := func(p int) models.Rate { return models.Rate(p) * models.MaxRate / 100 }
+
+	tests := []struct {
+		name     string
+		price    models.Money
+		quantity int
+		coupon   *models.Coupon
+		app      config.AppConfig
+		want     models.PriceBreakdown
+	}{
+		{"no fee or tax", 2500, 2, nil, config.AppConfig{},
+			models.PriceBreakdown{Subtotal: 5000, Total: 5000}},
+		{"flat fee per ticket", 2500, 2, nil, config.AppConfig{ServiceFee: models.ServiceFee{PerTicket: 150}},
+			models.PriceBreakdown{Subtotal: 5000, Fees: 300, Total: 5300}},
+		{"rate fee and tax", 2500, 2, nil, config.AppConfig{ServiceFee: models.ServiceFee{Rate: percent(10)}, TaxRate: percent(20)},
+			models.PriceBreakdown{Subtotal: 5000, Fees: 500, Tax: 1100, Total: 6600}},
+		{"free event carries no fee", 0, 3, nil, config.AppConfig{ServiceFee: models.ServiceFee{PerTicket: 150}, TaxRate: percent(20)},
+			models.PriceBreakdown{}},
+		{"full discount carries no fee", 2500, 1, &models.Coupon{PercentOff: 100},
+			config.AppConfig{ServiceFee: models.ServiceFee{PerTicket: 150}, TaxRate: percent(20)},
+			models.PriceBreakdown{Subtotal: 2500, Discount: 2500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := priceBooking(tt.price, tt.quantity, tt.coupon, "USD", &tt.app)
+			if err != nil {
+				t.Fatalf("priceBooking: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("priceBooking = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPostgresAvailabilityEstimateMatchesQuote checks the availability
+// estimate charges the same fees and tax as a quote for the same seats
+func TestPostgresAvailabilityEstimateMatchesQuote(t *testing.T) {
+	database := testDB(t)
+	cfg := testConfig()
+	cfg.App.ServiceFee = models.ServiceFee{PerTicket: 150}
+	cfg.App.TaxRate = models.MaxRate / 10
+	eventRepo := NewEventRepository(database, nil, nil, testLogger(), cfg)
+	event := createTestEvent(t, eventRepo, 5, 2500)
+	ctx := context.Background()
+
+	check, err := eventRepo.CheckAvailability(ctx, event.ID, 3)
+	if err != nil {
+		t.Fatalf("CheckAvailability: %v", err)
+	}
+	quote, err := eventRepo.QuoteBooking(ctx, event.ID, &models.QuoteRequest{Quantity: 3})
+	if err != nil {
+		t.Fatalf("QuoteBooking: %v", err)
+	}
+
+	if check.EstimatedTotal != quote.Total {
+		t.Errorf("estimated total = %d, quote total = %d", check.EstimatedTotal, quote.Total)
+	}
+	if check.EstimatedTotal == event.Price.Mul(3) {
+		t.Errorf("estimated total %d leaves out fees and tax", check.EstimatedTotal)
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// serializeSession takes a transaction-scoped advisory lock on (event, session)
+// so concurrent lock requests from one session are counted one at a time.
+// Callers take it before any ticket row locks to keep a single lock order.
+func serializeSession(ctx context.Context, tx *sql.Tx, eventID int, session string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, eventID, session); err != nil {
+		return fmt.Errorf("failed to serialize session locks: %w", err)
+	}
+	return nil
+}
+
+// lockDurationInterval is the SQL interval a seat lock lasts: column, an
+// event's seat_lock_duration, or when that is NULL the default seconds bound
+// to parameter param. The column is cast to float8 so Postgres types the
+// parameter as float8 too; left as integer, a default with a fractional
+// second such as SEAT_LOCK_DURATION=90.5s fails to bind.
+func lockDurationInterval(column string, param int) string {
+	return fmt.Sprintf("make_interval(secs => COALESCE(%s::float8, $%d))", column, param)
+}
+
+// checkSessionLockLimit fails when granting extra more seats would leave the
+// session with more than limit live locks on the event. Locks older than the
+// event's seat lock duration (lockDuration if it has none) are about to be
+// released and are not counted. A limit of 0 disables the check.
+func checkSessionLockLimit(ctx context.Context, tx *sql.Tx, eventID int, session string, extra, limit int, lockDuration time.Duration) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	countQuery := `
+		SELECT COUNT(*) 
+		FROM tickets 
+		WHERE event_id = $1 AND status = 'locked' AND locked_by = $2 
+		AND updated_at > NOW() - ` + lockDurationInterval("(SELECT seat_lock_duration FROM events WHERE id = $1)", 3)
+
+	var held int
+	if err := tx.QueryRowContext(ctx, countQuery, eventID, session, lockDuration.Seconds()).Scan(&held); err != nil {
+		return fmt.Errorf("failed to count session locks: %w", err)
+	}
+
+	if held+extra > limit {
+		return &SessionLockLimitError{Limit: limit, Held: held, Requested: extra}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package seating
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Placeholders supported in a seat label format
+const (
+	RowToken = "{row}"
+	NumToken = "{num}"
+)
+
+// MaxLabelLength matches the tickets.seat_no column size
+const MaxLabelLength = 50
+
+// GenerateLabels builds seat labels for an event. An empty format keeps the
+// legacy S001..SNNN scheme. A format containing {row} spreads the seats over
+// the given number of rows (A, B, ... Z, AA, ...) and {num} restarts at 1 in
+// every row; without {row}, {num} runs from 1 to total.
+func GenerateLabels(format string, rows, total int) ([]string, error) {
+	if total <= 0 {
+		return nil, fmt.Errorf("total seats must be positive")
+	}
+
+	if format == "" {
+		labels := make([]string, 0, total)
+		for i := 1; i <= total; i++ {
+			labels = append(labels, fmt.Sprintf("S%03d", i))
+		}
+		return labels, nil
+	}
+
+	if !strings.Contains(format, NumToken) {
+		return nil, fmt.Errorf("seat label format must contain %s", NumToken)
+	}
+
+	if !strings.Contains(format, RowToken) {
+		labels := make([]string, 0, total)
+		for i := 1; i <= total; i++ {
+			labels = append(labels, strings.ReplaceAll(format, NumToken, strconv.Itoa(i)))
+		}
+		return labels, ValidateLabels(labels, total)
+	}
+
+	if rows <= 0 {
+		return nil, fmt.Errorf("seat rows must be positive when the format contains %s", RowToken)
+	}
+	if rows > total {
+		return nil, fmt.Errorf("seat rows (%d) cannot exceed total seats (%d)", rows, total)
+	}
+
+	seatsPerRow := SeatsPerRow(rows, total)
+	labels := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		label := strings.ReplaceAll(format, RowToken, RowName(i/seatsPerRow))
+		label = strings.ReplaceAll(label, NumToken, strconv.Itoa(i%seatsPerRow+1))
+		labels = append(labels, label)
+	}
+
+	return labels, ValidateLabels(labels, total)
+}
+
+// ValidateLabels checks that an explicit list of seat labels is usable:
+// exactly total entries, none empty or too long, and no duplicates
+func ValidateLabels(labels []string, total int) error {
+	if len(labels) != total {
+		return fmt.Errorf("seat label count (%d) must equal total tickets (%d)", len(labels), total)
+	}
+
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		if err := CheckLabel(label); err != nil {
+			return err
+		}
+		if _, ok := seen[label]; ok {
+			return fmt.Errorf("duplicate seat label %q", label)
+		}
+		seen[label] = struct{}{}
+	}
+
+	return nil
+}
+
+// CheckLabel rejects seat labels that no event could contain: empty, too
+// long for the column, or holding control characters. It needs no event
+// context, so handlers can run it before touching the database.
+func CheckLabel(label string) error {
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("seat labels cannot be empty")
+	}
+	if len(label) > MaxLabelLength {
+		return fmt.Errorf("seat label %q exceeds %d characters", label, MaxLabelLength)
+	}
+	if !utf8.ValidString(label) {
+		return fmt.Errorf("seat label %q is not valid UTF-8", label)
+	}
+	for _, r := range label {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("seat label %q contains control characters", label)
+		}
+	}
+	return nil
+}
+
+// MatchesFormat reports whether label could have been generated from format.
+// An empty format matches anything, since such events use either the legacy
+// scheme or an explicit label list.
+func MatchesFormat(format, label string) bool {
+	if format == "" {
+		return true
+	}
+
+	pattern := regexp.QuoteMeta(format)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(RowToken), "[A-Z]+")
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(NumToken), "[1-9][0-9]*")
+
+	re, err := regexp.Compile("^" + pattern + "$")
+	if err != nil {
+		return true
+	}
+	return re.MatchString(label)
+}
+
+// SeatsPerRow returns how many seats each row holds; the last row may be shorter
+func SeatsPerRow(rows, total int) int {
+	return (total + rows - 1) / rows
+}
+
+// RowName converts a zero-based row index into a spreadsheet-style name
+// (0 -> A, 25 -> Z, 26 -> AA)
+func RowName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,45 @@
+package seating
+
+import "testing"
+
+func TestMatchesFormat(t *testing.T) {
+	tests := []struct {
+		format, label string
+		want          bool
+	}{
+		{"", "S001", true},
+		{"", "anything at all", true},
+		{"{row}{num}", "A1", true},
+		{"{row}{num}", "AA12", true},
+		{"{row}{num}", "a1", false},
+		{"{row}{num}", "A01", false},
+		{"{row}{num}", "A", false},
+		{"{row}{num}", "1A", false},
+		{"{row}-{num}", "B-7", true},
+		{"{row}-{num}", "B7", false},
+		{"Table {num}", "Table 12", true},
+		{"Table {num}", "Table 0", false},
+		{"Table {num}", "Table 12 ", false},
+		{"GA.{num}", "GAx1", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesFormat(tt.format, tt.label); got != tt.want {
+			t.Errorf("MatchesFormat(%q, %q) = %v, want %v", tt.format, tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratedLabelsMatchFormat(t *testing.T) {
+	for _, format := range []string{"{row}{num}", "{row}-{num}", "Seat {num}"} {
+		labels, err := GenerateLabels(format, 3, 30)
+		if err != nil {
+			t.Fatalf("GenerateLabels(%q): %v", format, err)
+		}
+		for _, label := range labels {
+			if !MatchesFormat(format, label) {
+				t.Errorf("generated label %q does not match its format %q", label, format)
+			}
+		}
+	}
+}
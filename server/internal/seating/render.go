@@ -0,0 +1,94 @@
+package seating
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+const (
+	// seatPixels and gapPixels size one seat cell at scale 1
+	seatPixels = 8
+	gapPixels  = 2
+	// maxRenderColumns wraps long rows so an unsectioned venue of thousands
+	// of seats still renders as a readable block instead of one thin line
+	maxRenderColumns = 100
+	// MaxRenderScale bounds ?scale= so a large venue can't produce a huge image
+	MaxRenderScale = 4
+)
+
+var (
+	backgroundColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	statusColors    = map[models.TicketStatus]color.RGBA{
+		models.TicketAvailable: {R: 0x2e, G: 0xa0, B: 0x43, A: 0xff},
+		models.TicketLocked:    {R: 0xf0, G: 0xa2, B: 0x02, A: 0xff},
+		models.TicketReserved:  {R: 0x1f, G: 0x6f, B: 0xeb, A: 0xff},
+		models.TicketSold:      {R: 0x8b, G: 0x94, B: 0x9e, A: 0xff},
+	}
+	unknownStatusColor = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xff}
+)
+
+// RenderPNG draws a seat map as a grid of coloured squares, one line per row
+// and a blank line between sections. Rows longer than maxRenderColumns wrap.
+func RenderPNG(seatMap *models.SeatMap, scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	if scale > MaxRenderScale {
+		scale = MaxRenderScale
+	}
+
+	// Lay out every seat on a (line, column) grid first to size the image
+	type cell struct {
+		line, column int
+		status       models.TicketStatus
+	}
+	var cells []cell
+	line, columns := 0, 1
+	for i, section := range seatMap.Sections {
+		if i > 0 {
+			line++
+		}
+		for _, row := range section.Rows {
+			for j, seat := range row.Seats {
+				if j > 0 && j%maxRenderColumns == 0 {
+					line++
+				}
+				column := j % maxRenderColumns
+				cells = append(cells, cell{line: line, column: column, status: seat.Status})
+				if column+1 > columns {
+					columns = column + 1
+				}
+			}
+			line++
+		}
+	}
+	if line == 0 {
+		line = 1
+	}
+
+	pitch := (seatPixels + gapPixels) * scale
+	img := image.NewRGBA(image.Rect(0, 0, columns*pitch+gapPixels*scale, line*pitch+gapPixels*scale))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: backgroundColor}, image.Point{}, draw.Src)
+
+	for _, c := range cells {
+		fill, ok := statusColors[c.status]
+		if !ok {
+			fill = unknownStatusColor
+		}
+		x := gapPixels*scale + c.column*pitch
+		y := gapPixels*scale + c.line*pitch
+		rect := image.Rect(x, y, x+seatPixels*scale, y+seatPixels*scale)
+		draw.Draw(img, rect, &image.Uniform{C: fill}, image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
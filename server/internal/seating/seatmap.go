@@ -0,0 +1,150 @@
+package seating
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// DefaultSection names seats whose label carries no section prefix
+const DefaultSection = "General"
+
+// Position is a seat's place in the venue layout
+type Position struct {
+	Section string
+	Row     string
+	Number  int
+}
+
+// ParseLabel derives a position from a seat label. An optional section prefix
+// is separated by the last '-' ("BALC-A12"), the row is the non-numeric part
+// and the number is the trailing digits ("A12" -> row A, seat 12).
+func ParseLabel(label string) Position {
+	pos := Position{Section: DefaultSection}
+
+	if i := strings.LastIndex(label, "-"); i > 0 {
+		pos.Section = label[:i]
+		label = label[i+1:]
+	}
+
+	digits := len(label)
+	for digits > 0 && unicode.IsDigit(rune(label[digits-1])) {
+		digits--
+	}
+
+	pos.Row = label[:digits]
+	if n, err := strconv.Atoi(label[digits:]); err == nil {
+		pos.Number = n
+	}
+
+	return pos
+}
+
+// Positions maps every seat label of an event to its position. Events created
+// with a {row} format are laid out from the format; anything else is parsed.
+func Positions(event *models.Event, labels []string) map[string]Position {
+	positions := make(map[string]Position, len(labels))
+
+	if strings.Contains(event.SeatLabelFormat, RowToken) && event.SeatRows > 0 {
+		generated, err := GenerateLabels(event.SeatLabelFormat, event.SeatRows, event.TotalTickets)
+		if err == nil {
+			seatsPerRow := SeatsPerRow(event.SeatRows, event.TotalTickets)
+			for i, label := range generated {
+				positions[label] = Position{
+					Section: DefaultSection,
+					Row:     RowName(i / seatsPerRow),
+					Number:  i%seatsPerRow + 1,
+				}
+			}
+		}
+	}
+
+	for _, label := range labels {
+		if _, ok := positions[label]; !ok {
+			positions[label] = ParseLabel(label)
+		}
+	}
+
+	return positions
+}
+
+// BuildSeatMap groups tickets by section and row with per-section counts
+func BuildSeatMap(event *models.Event, tickets []*models.Ticket) *models.SeatMap {
+	labels := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		labels = append(labels, ticket.SeatNo)
+	}
+	positions := Positions(event, labels)
+
+	sections := make(map[string]*models.SeatMapSection)
+	rows := make(map[string]map[string]*models.SeatMapRow)
+	seatMap := &models.SeatMap{EventID: event.ID}
+
+	for _, ticket := range tickets {
+		pos := positions[ticket.SeatNo]
+
+		section, ok := sections[pos.Section]
+		if !ok {
+			section = &models.SeatMapSection{Name: pos.Section}
+			sections[pos.Section] = section
+			rows[pos.Section] = make(map[string]*models.SeatMapRow)
+		}
+
+		row, ok := rows[pos.Section][pos.Row]
+		if !ok {
+			row = &models.SeatMapRow{Name: pos.Row}
+			rows[pos.Section][pos.Row] = row
+		}
+
+		row.Seats = append(row.Seats, models.SeatMapSeat{
+			TicketID: ticket.ID,
+			SeatNo:   ticket.SeatNo,
+			Number:   pos.Number,
+			Status:   ticket.Status,
+		})
+		section.Counts.Add(ticket.Status)
+		seatMap.Counts.Add(ticket.Status)
+	}
+
+	sectionNames := make([]string, 0, len(sections))
+	for name := range sections {
+		sectionNames = append(sectionNames, name)
+	}
+	sort.Slice(sectionNames, func(i, j int) bool {
+		// Keep the unnamed section first, then alphabetical
+		if sectionNames[i] == DefaultSection || sectionNames[j] == DefaultSection {
+			return sectionNames[i] == DefaultSection && sectionNames[j] != DefaultSection
+		}
+		return sectionNames[i] < sectionNames[j]
+	})
+
+	for _, name := range sectionNames {
+		section := sections[name]
+		for _, row := range rows[name] {
+			sort.Slice(row.Seats, func(i, j int) bool {
+				if row.Seats[i].Number != row.Seats[j].Number {
+					return row.Seats[i].Number < row.Seats[j].Number
+				}
+				return row.Seats[i].SeatNo < row.Seats[j].SeatNo
+			})
+			section.Rows = append(section.Rows, *row)
+		}
+		sort.Slice(section.Rows, func(i, j int) bool {
+			return lessRowName(section.Rows[i].Name, section.Rows[j].Name)
+		})
+		seatMap.Sections = append(seatMap.Sections, *section)
+	}
+
+	return seatMap
+}
+
+// lessRowName orders rows the way RowName produces them: A..Z before AA
+func lessRowName(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
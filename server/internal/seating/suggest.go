@@ -0,0 +1,84 @@
+package seating
+
+import (
+	"github.com/milinddethe15/ticket-booking/internal/models"
+)
+
+// Suggest picks quantity available seats from a seat map, preferring a run of
+// adjacent seats in one row. Sections and rows are searched in seat map order,
+// so the first row with a fitting run wins; within that row the run closest to
+// the middle is chosen. Without any such run it falls back to the first
+// available seats in layout order and reports them as not adjacent. It returns
+// nil when fewer than quantity seats are available.
+func Suggest(seatMap *models.SeatMap, quantity int) ([]string, bool) {
+	if quantity < 1 {
+		return nil, false
+	}
+
+	for _, section := range seatMap.Sections {
+		for _, row := range section.Rows {
+			if run := bestRun(row.Seats, quantity); run != nil {
+				return run, true
+			}
+		}
+	}
+
+	var seats []string
+	for _, section := range seatMap.Sections {
+		for _, row := range section.Rows {
+			for _, seat := range row.Seats {
+				if seat.Status != models.TicketAvailable {
+					continue
+				}
+				seats = append(seats, seat.SeatNo)
+				if len(seats) == quantity {
+					return seats, false
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// bestRun finds the window of quantity consecutive available seats whose
+// centre is nearest the row's centre. Seats are adjacent when their numbers
+// follow each other; seats without a number are never adjacent.
+func bestRun(seats []models.SeatMapSeat, quantity int) []string {
+	best := -1
+	bestDistance := 0
+	rowCentre := len(seats) - 1 // doubled to stay in integers
+
+	runStart := 0
+	for i, seat := range seats {
+		adjacent := i > 0 && seats[i-1].Status == models.TicketAvailable &&
+			seat.Number > 0 && seat.Number == seats[i-1].Number+1
+		if seat.Status != models.TicketAvailable {
+			runStart = i + 1
+			continue
+		}
+		if !adjacent {
+			runStart = i
+		}
+
+		if start := i - quantity + 1; start >= runStart {
+			distance := start + i - rowCentre
+			if distance < 0 {
+				distance = -distance
+			}
+			if best < 0 || distance < bestDistance {
+				best, bestDistance = start, distance
+			}
+		}
+	}
+
+	if best < 0 {
+		return nil
+	}
+
+	run := make([]string, 0, quantity)
+	for _, seat := range seats[best : best+quantity] {
+		run = append(run, seat.SeatNo)
+	}
+	return run
+}
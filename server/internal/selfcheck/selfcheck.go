@@ -0,0 +1,215 @@
+// Package selfcheck verifies on startup that the database schema and the
+// configuration are what this build of the server expects. Deploying new code
+// against a database that hasn't been migrated otherwise only shows up later,
+// as confusing query errors on whichever endpoint first touches a new column.
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+	"github.com/milinddethe15/ticket-booking/internal/db"
+)
+
+// Finding is one mismatch the self-check found
+type Finding struct {
+	Check   string // "schema", "migrations" or "config"
+	Message string
+	Table   string
+	Column  string
+	Setting string
+}
+
+// requiredColumns lists the columns the server queries, per table; a migration
+// that adds a column the code relies on adds it here too
+var requiredColumns = map[string][]string{
+	"events": {
+		"id", "name", "description", "venue", "start_time", "end_time", "total_tickets", "available_tickets",
+		"price", "currency", "seat_label_format", "seat_rows", "max_per_booking", "max_per_user", "external_ref",
+		"sales_close_offset", "series_id", "seat_lock_duration", "is_sales_open", "image_url", "banner_url",
+		"created_at", "updated_at",
+	},
+	"tickets": {
+		"id", "event_id", "seat_no", "status", "hold_id", "locked_by", "locked_at", "checked_in_at", "checked_in_by",
+		"created_at", "updated_at",
+	},
+	"bookings": {
+		"id", "user_id", "event_id", "ticket_ids", "quantity", "total_amount", "currency", "status", "booking_ref",
+		"expires_at", "coupon_code", "discount_amount", "payment_ref", "guest_name", "guest_email", "guest_phone",
+		"subtotal", "fees_amount", "tax_amount", "created_at", "updated_at",
+	},
+	"holds":        {"id", "event_id", "session_id", "status", "expires_at", "created_at", "updated_at"},
+	"coupons":      {"code", "percent_off", "amount_off", "currency", "event_id", "max_uses", "times_used", "active", "expires_at"},
+	"event_series": {"id", "name", "frequency", "created_at"},
+}
+
+// Run checks the schema against requiredColumns, the applied migrations against
+// those embedded in migrations, and the configuration for combinations that
+// Load accepts but that cannot work. An error means a check could not run.
+func Run(ctx context.Context, database *db.DB, cfg *config.Config, migrations fs.FS) ([]Finding, error) {
+	existing, err := loadColumns(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	findings := checkSchema(existing)
+
+	pending, ok, err := database.PendingMigrations(ctx, migrations)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for _, name := range pending {
+			findings = append(findings, Finding{
+				Check:   "migrations",
+				Message: fmt.Sprintf("migration %s has not been applied", name),
+			})
+		}
+	}
+
+	return append(findings, checkConfig(cfg)...), nil
+}
+
+// loadColumns reads the columns of the required tables from the current schema
+func loadColumns(ctx context.Context, database *db.DB) (map[string]map[string]bool, error) {
+	tables := make([]string, 0, len(requiredColumns))
+	for table := range requiredColumns {
+		tables = append(tables, table)
+	}
+
+	query := `
+		SELECT table_name, column_name 
+		FROM information_schema.columns 
+		WHERE table_schema = current_schema() AND table_name = ANY($1)`
+
+	rows, err := database.QueryContext(ctx, query, pq.Array(tables))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("failed to scan schema column: %w", err)
+		}
+		if existing[table] == nil {
+			existing[table] = make(map[string]bool)
+		}
+		existing[table][column] = true
+	}
+	return existing, rows.Err()
+}
+
+// checkSchema reports every required table missing from existing, and every
+// required column missing from a table that is there
+func checkSchema(existing map[string]map[string]bool) []Finding {
+	tables := make([]string, 0, len(requiredColumns))
+	for table := range requiredColumns {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var findings []Finding
+	for _, table := range tables {
+		columns, ok := existing[table]
+		if !ok {
+			findings = append(findings, Finding{
+				Check:   "schema",
+				Message: fmt.Sprintf("table %s does not exist", table),
+				Table:   table,
+			})
+			continue
+		}
+
+		for _, column := range requiredColumns[table] {
+			if !columns[column] {
+				findings = append(findings, Finding{
+					Check:   "schema",
+					Message: fmt.Sprintf("column %s.%s does not exist", table, column),
+					Table:   table,
+					Column:  column,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// checkConfig reports settings that each pass Load but do not work together
+func checkConfig(cfg *config.Config) []Finding {
+	var findings []Finding
+	add := func(setting, format string, args ...interface{}) {
+		findings = append(findings, Finding{Check: "config", Setting: setting, Message: fmt.Sprintf(format, args...)})
+	}
+
+	app, database := &cfg.App, &cfg.Database
+	if app.SeatLockDuration <= 0 {
+		add("SEAT_LOCK_DURATION", "SEAT_LOCK_DURATION must be positive, got %s", app.SeatLockDuration)
+	}
+	if app.BookingExpiration <= 0 {
+		add("BOOKING_EXPIRATION", "BOOKING_EXPIRATION must be positive, got %s", app.BookingExpiration)
+	}
+	if app.CleanupInterval <= 0 {
+		add("CLEANUP_INTERVAL", "CLEANUP_INTERVAL must be positive, got %s", app.CleanupInterval)
+	}
+	if app.MaxHoldDuration > 0 && app.MaxHoldDuration < app.SeatLockDuration {
+		add("MAX_HOLD_DURATION", "MAX_HOLD_DURATION (%s) is shorter than SEAT_LOCK_DURATION (%s)",
+			app.MaxHoldDuration, app.SeatLockDuration)
+	}
+	if app.RateLimitBackend != "memory" && app.RateLimitBackend != "redis" {
+		add("RATE_LIMIT_BACKEND", "RATE_LIMIT_BACKEND must be memory or redis, got %q", app.RateLimitBackend)
+	}
+	if database.MaxOpenConns > 0 && database.MaxIdleConns > database.MaxOpenConns {
+		add("DB_MAX_IDLE_CONNS", "DB_MAX_IDLE_CONNS (%d) is more than DB_MAX_OPEN_CONNS (%d)",
+			database.MaxIdleConns, database.MaxOpenConns)
+	}
+	// Booking transactions past the pool size just queue for a connection,
+	// which is what the admission cap is there to prevent
+	if database.MaxOpenConns > 0 && app.MaxConcurrentBookings >= database.MaxOpenConns {
+		add("MAX_CONCURRENT_BOOKINGS_PER_EVENT", "MAX_CONCURRENT_BOOKINGS_PER_EVENT (%d) should be below DB_MAX_OPEN_CONNS (%d)",
+			app.MaxConcurrentBookings, database.MaxOpenConns)
+	}
+	return findings
+}
+
+// Report logs each finding and a summary line
+func Report(logger *logrus.Logger, findings []Finding) {
+	if len(findings) == 0 {
+		logger.WithField("tables", len(requiredColumns)).Info("Startup self-check passed")
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.Check]++
+		fields := logrus.Fields{"check": f.Check}
+		if f.Table != "" {
+			fields["table"] = f.Table
+		}
+		if f.Column != "" {
+			fields["column"] = f.Column
+		}
+		if f.Setting != "" {
+			fields["setting"] = f.Setting
+		}
+		logger.WithFields(fields).Warn("Self-check: " + f.Message)
+	}
+
+	checks := make([]string, 0, len(counts))
+	for check, n := range counts {
+		checks = append(checks, fmt.Sprintf("%s=%d", check, n))
+	}
+	sort.Strings(checks)
+	logger.WithFields(logrus.Fields{
+		"findings": len(findings),
+		"by_check": strings.Join(checks, ","),
+	}).Warn("Startup self-check found problems")
+}
@@ -0,0 +1,99 @@
+package selfcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/milinddethe15/ticket-booking/internal/config"
+)
+
+// fullSchema returns every required table with all of its columns
+func fullSchema() map[string]map[string]bool {
+	existing := make(map[string]map[string]bool)
+	for table, columns := range requiredColumns {
+		existing[table] = make(map[string]bool)
+		for _, column := range columns {
+			existing[table][column] = true
+		}
+	}
+	return existing
+}
+
+func TestCheckSchemaComplete(t *testing.T) {
+	if findings := checkSchema(fullSchema()); len(findings) != 0 {
+		t.Errorf("checkSchema on a complete schema = %v, want no findings", findings)
+	}
+}
+
+func TestCheckSchemaMissingTable(t *testing.T) {
+	existing := fullSchema()
+	delete(existing, "holds")
+
+	findings := checkSchema(existing)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Check != "schema" || f.Table != "holds" || f.Column != "" {
+		t.Errorf("finding = %+v, want a schema finding for table holds", f)
+	}
+	if f.Message != "table holds does not exist" {
+		t.Errorf("message = %q", f.Message)
+	}
+}
+
+func TestCheckSchemaEmptyDatabase(t *testing.T) {
+	findings := checkSchema(map[string]map[string]bool{})
+	if len(findings) != len(requiredColumns) {
+		t.Fatalf("got %d findings, want one per required table (%d)", len(findings), len(requiredColumns))
+	}
+	// Tables are reported in a stable, sorted order
+	for i := 1; i < len(findings); i++ {
+		if findings[i-1].Table >= findings[i].Table {
+			t.Errorf("findings not sorted by table: %s before %s", findings[i-1].Table, findings[i].Table)
+		}
+	}
+	for _, f := range findings {
+		if f.Column != "" {
+			t.Errorf("missing table %s also reported column %s", f.Table, f.Column)
+		}
+	}
+}
+
+func TestCheckSchemaMissingColumn(t *testing.T) {
+	existing := fullSchema()
+	delete(existing["events"], "is_sales_open")
+
+	findings := checkSchema(existing)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %v", len(findings), findings)
+	}
+	if f := findings[0]; f.Table != "events" || f.Column != "is_sales_open" {
+		t.Errorf("finding = %+v, want events.is_sales_open", f)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	valid := func() *config.Config {
+		return &config.Config{
+			App: config.AppConfig{
+				SeatLockDuration:  3 * time.Minute,
+				BookingExpiration: 15 * time.Minute,
+				CleanupInterval:   time.Minute,
+				RateLimitBackend:  "memory",
+			},
+			Database: config.DatabaseConfig{MaxOpenConns: 25, MaxIdleConns: 5},
+		}
+	}
+
+	if findings := checkConfig(valid()); len(findings) != 0 {
+		t.Errorf("checkConfig on a valid config = %v, want no findings", findings)
+	}
+
+	cfg := valid()
+	cfg.Database.MaxIdleConns = 50
+	findings := checkConfig(cfg)
+	if len(findings) != 1 || findings[0].Setting != "DB_MAX_IDLE_CONNS" {
+		t.Errorf("findings = %v, want one for DB_MAX_IDLE_CONNS", findings)
+	}
+}
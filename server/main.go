@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 	"os"
 	"os/signal"
@@ -9,13 +10,19 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
+	"github.com/milinddethe15/ticket-booking/internal/admission"
+	"github.com/milinddethe15/ticket-booking/internal/cache"
 	"github.com/milinddethe15/ticket-booking/internal/config"
 	"github.com/milinddethe15/ticket-booking/internal/db"
+	"github.com/milinddethe15/ticket-booking/internal/expiry"
 	"github.com/milinddethe15/ticket-booking/internal/handlers"
 	"github.com/milinddethe15/ticket-booking/internal/middleware"
 	"github.com/milinddethe15/ticket-booking/internal/repository"
+	"github.com/milinddethe15/ticket-booking/internal/selfcheck"
+	"github.com/milinddethe15/ticket-booking/migrations"
 )
 
 func main() {
@@ -26,7 +33,7 @@ func main() {
 	}
 
 	// Setup logger
-	logger := setupLogger(cfg.App.LogLevel)
+	logger := setupLogger(cfg.App.LogLevel, cfg.App.LogFormat)
 	logger.Info("Starting ticket booking service")
 
 	// Connect to database
@@ -34,22 +41,85 @@ func main() {
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to connect to database")
 	}
+	database.SetSlowQueryThreshold(cfg.App.SlowQueryThreshold)
 	defer database.Close()
 
+	// Bring the schema up to date before anything queries it
+	if cfg.Database.RunMigrations {
+		if err := database.Migrate(context.Background(), migrations.FS); err != nil {
+			logger.WithError(err).Fatal("Failed to run database migrations")
+		}
+	}
+
+	// Check the schema and config before serving, so an unmigrated database is
+	// reported up front rather than as query errors on live traffic
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	findings, err := selfcheck.Run(checkCtx, database, cfg, migrations.FS)
+	cancelCheck()
+	if err != nil {
+		if cfg.App.StrictStartup {
+			logger.WithError(err).Fatal("Startup self-check could not run")
+		}
+		logger.WithError(err).Warn("Startup self-check could not run")
+	} else {
+		selfcheck.Report(logger, findings)
+		if len(findings) > 0 && cfg.App.StrictStartup {
+			logger.WithField("findings", len(findings)).Fatal("Refusing to start with STRICT_STARTUP=true")
+		}
+	}
+
+	// Connect to Redis when configured
+	redisClient, err := db.NewRedisClient(&cfg.Redis, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to connect to redis")
+	}
+	if redisClient != nil {
+		defer redisClient.Close()
+	}
+
 	// Initialize repositories with configuration
-	bookingRepo := repository.NewBookingRepository(database, logger, cfg)
-	eventRepo := repository.NewEventRepository(database, logger, cfg)
+	lockExpiry := expiry.NewScheduler(logger)
+	// No payment gateway is integrated yet, so confirming with a payment_ref is
+	// refused; confirms without one (free events, manual settlement) still work
+	bookingRepo := repository.NewBookingRepository(database, lockExpiry, nil, logger, cfg)
+	eventCache := cache.NewEventCache(redisClient, cfg.App.EventCacheTTL, logger)
+	eventRepo := repository.NewEventRepository(database, eventCache, lockExpiry, logger, cfg)
+	holdRepo := repository.NewHoldRepository(database, logger, cfg)
 
 	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	eventHandler := handlers.NewEventHandler(eventRepo, logger)
-	bookingHandler := handlers.NewBookingHandler(bookingRepo, eventRepo, logger)
+	healthHandler := handlers.NewHealthHandler(database)
+	eventHandler := handlers.NewEventHandler(eventRepo, logger, cfg)
+	bookingHandler := handlers.NewBookingHandler(bookingRepo, eventRepo, admission.NewLimiter(cfg.App.MaxConcurrentBookings), logger, cfg)
+	holdHandler := handlers.NewHoldHandler(holdRepo, logger, cfg)
+	adminHandler := handlers.NewAdminHandler(eventRepo, bookingRepo, logger)
+	checkInHandler := handlers.NewCheckInHandler(eventRepo, logger)
 
 	// Start background cleanup routine for expired seat locks with configurable interval
-	go startSeatLockCleanup(eventRepo, logger, cfg.App.CleanupInterval)
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	defer stopCleanup()
+	go startSeatLockCleanup(cleanupCtx, eventRepo, holdRepo, logger, cfg.App.CleanupInterval, cfg.App.ReconcileOnCleanup)
+	// Reconciling corrects drift in every event's available_tickets counter,
+	// which bounds how long the fast availability read can be wrong
+	if cfg.App.ReconcileInterval > 0 {
+		go startPeriodicJob(cleanupCtx, logger, "availability reconcile", cfg.App.ReconcileInterval, eventRepo.ReconcileAllAvailability)
+	}
+	// Safety net for tickets left reserved without a pending booking
+	if cfg.App.OrphanCleanupInterval > 0 {
+		go startPeriodicJob(cleanupCtx, logger, "orphaned reservation cleanup", cfg.App.OrphanCleanupInterval, eventRepo.ReleaseOrphanedReservations)
+	}
+
+	// Release locks taken by this instance as soon as they expire; the sweep
+	// above covers everything else
+	go lockExpiry.Run(cleanupCtx, eventRepo.ExpireSeatLock)
 
 	// Setup HTTP server
-	router := setupRouter(cfg, logger, healthHandler, eventHandler, bookingHandler)
+	drainer := middleware.NewDrainer()
+	var latency *middleware.LatencyTracker
+	if cfg.App.LatencyReportInterval > 0 {
+		latency = middleware.NewLatencyTracker(logger)
+		go latency.Run(cleanupCtx, cfg.App.LatencyReportInterval)
+	}
+	router := setupRouter(cfg, logger, redisClient, drainer, latency, healthHandler, eventHandler, bookingHandler, holdHandler, adminHandler, checkInHandler)
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -61,8 +131,23 @@ func main() {
 
 	// Start server in goroutine
 	go func() {
-		logger.WithField("port", cfg.Server.Port).Info("Starting HTTP server")
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if cfg.Server.TLSEnabled() {
+			// HTTP/2 is negotiated automatically over TLS
+			server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			logger.WithFields(logrus.Fields{
+				"port": cfg.Server.Port,
+				"mode": "https",
+			}).Info("Starting HTTPS server with HTTP/2")
+			err = server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			logger.WithFields(logrus.Fields{
+				"port": cfg.Server.Port,
+				"mode": "http",
+			}).Info("Starting HTTP server")
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Fatal("Failed to start server")
 		}
 	}()
@@ -72,22 +157,34 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
+	// Turn new requests away (and fail readiness) while in-flight ones finish
+	inFlight := drainer.Start()
+	logger.WithFields(logrus.Fields{
+		"shutdown_timeout": cfg.Server.ShutdownTimeout,
+		"in_flight":        inFlight,
+	}).Info("Shutting down server...")
+
+	// Stop background cleanup so it doesn't start a run against a closing pool
+	stopCleanup()
 
 	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Fatal("Server forced to shutdown")
+		logger.WithError(err).WithField("in_flight", drainer.InFlight()).Fatal("Server forced to shutdown")
 	}
 
 	logger.Info("Server exited")
 }
 
-func setupLogger(logLevel string) *logrus.Logger {
+func setupLogger(logLevel, logFormat string) *logrus.Logger {
 	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	if logFormat == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
 
 	level, err := logrus.ParseLevel(logLevel)
 	if err != nil {
@@ -95,10 +192,15 @@ func setupLogger(logLevel string) *logrus.Logger {
 	}
 	logger.SetLevel(level)
 
+	// Send Gin's own output (debug route listing, warnings) through logrus so
+	// it shares the configured format and level instead of raw stdout lines
+	gin.DefaultWriter = logger.WriterLevel(logrus.DebugLevel)
+	gin.DefaultErrorWriter = logger.WriterLevel(logrus.ErrorLevel)
+
 	return logger
 }
 
-func setupRouter(cfg *config.Config, logger *logrus.Logger, healthHandler *handlers.HealthHandler, eventHandler *handlers.EventHandler, bookingHandler *handlers.BookingHandler) *gin.Engine {
+func setupRouter(cfg *config.Config, logger *logrus.Logger, redisClient *redis.Client, drainer *middleware.Drainer, latency *middleware.LatencyTracker, healthHandler *handlers.HealthHandler, eventHandler *handlers.EventHandler, bookingHandler *handlers.BookingHandler, holdHandler *handlers.HoldHandler, adminHandler *handlers.AdminHandler, checkInHandler *handlers.CheckInHandler) *gin.Engine {
 	// Set Gin mode
 	if cfg.App.LogLevel == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -108,17 +210,41 @@ func setupRouter(cfg *config.Config, logger *logrus.Logger, healthHandler *handl
 
 	router := gin.New()
 
+	// ClientIP() feeds request logs, the per-IP rate limiter and the admin
+	// audit trail, so X-Forwarded-For is only honoured from known proxies.
+	// With none configured the connection's remote address is used.
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.WithError(err).Fatal("Invalid TRUSTED_PROXIES")
+	}
+
 	// Apply global middleware
-	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.ErrorHandler(logger))
 	router.Use(middleware.Logger(logger))
+	if latency != nil {
+		router.Use(latency.Middleware())
+	}
 	router.Use(middleware.CORS())
 	router.Use(middleware.Security())
 	router.Use(middleware.RequestID())
-	router.Use(middleware.RequestTimeout(30 * time.Second))
-	router.Use(middleware.RateLimiter(cfg.App.RateLimitRPS))
+	// Reject new work once shutdown starts, before it reaches the rate limiter
+	router.Use(drainer.Middleware())
+	// Ahead of RequestTimeout, so a timeout answer is flushed through it too.
+	// Profiles are already compressed and some are streamed.
+	if cfg.Server.GzipEnabled {
+		router.Use(middleware.Gzip(cfg.Server.GzipMinSize, append([]string{"/debug/pprof"}, cfg.Server.GzipExcludePaths...)))
+	}
+	// Creating a large venue inserts every seat in one transaction, so it gets
+	// a longer budget than the default instead of being cancelled mid-way.
+	// Profiles run for their ?seconds= and are bounded by WRITE_TIMEOUT instead.
+	router.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout, map[string]time.Duration{
+		middleware.RouteKey(http.MethodPost, "/api/v1/events"):        cfg.Server.EventWriteTimeout,
+		middleware.RouteKey(http.MethodPost, "/api/v1/events/series"): cfg.Server.EventWriteTimeout,
+	}, []string{"/debug/pprof"}))
+	router.Use(rateLimiter(cfg, logger, redisClient))
 
 	// Health check routes (no rate limiting)
 	router.GET("/health", healthHandler.Health)
+	router.GET("/health/deep", healthHandler.DeepHealth)
 	router.GET("/ready", healthHandler.Ready)
 
 	// API routes
@@ -126,27 +252,79 @@ func setupRouter(cfg *config.Config, logger *logrus.Logger, healthHandler *handl
 	{
 		// Event routes
 		events := v1.Group("/events")
-		events.Use(middleware.Pagination())
 		{
-			events.GET("", eventHandler.GetEvents)
+			events.GET("", middleware.Pagination(cfg.App.DefaultPageSize, cfg.App.MaxPageSize, cfg.App.StrictPagination), eventHandler.GetEvents)
 			events.GET("/:id", eventHandler.GetEvent)
 			events.POST("", eventHandler.CreateEvent)
-			events.GET("/:id/tickets", eventHandler.GetAvailableTickets)
+			events.POST("/series", eventHandler.CreateSeries)
+			events.GET("/:id/tickets", middleware.Pagination(cfg.App.DefaultTicketPageSize, cfg.App.MaxTicketPageSize, cfg.App.StrictPagination), eventHandler.GetTickets)
 			events.GET("/:id/tickets/all", eventHandler.GetAllTickets)
+			events.GET("/:id/seatmap", eventHandler.GetSeatMap)
+			events.GET("/:id/seatmap.png", eventHandler.GetSeatMapImage)
+			events.GET("/:id/availability", eventHandler.CheckAvailability)
+			events.GET("/:id/availability/count", eventHandler.CountAvailable)
+			events.POST("/:id/quote", eventHandler.QuoteBooking)
+			events.GET("/:id/seats/suggest", eventHandler.SuggestSeats)
 			events.POST("/:id/seats/:seatNo/lock", eventHandler.LockSeat)
 			events.POST("/:id/seats/:seatNo/unlock", eventHandler.UnlockSeat)
+			events.POST("/:id/hold", holdHandler.CreateHold)
+			events.GET("/:id/holds", eventHandler.GetSessionHolds)
+			// Pausing sales is an organizer action and needs the admin key
+			events.POST("/:id/sales/toggle", middleware.AdminAuth(cfg.App.AdminAPIKey), eventHandler.ToggleSales)
 		}
 
 		// Booking routes
 		bookings := v1.Group("/bookings")
 		{
 			bookings.POST("", bookingHandler.BookTickets)
+			bookings.POST("/status", bookingHandler.GetBookingStatuses)
 			bookings.GET("/:id", bookingHandler.GetBooking)
 			bookings.POST("/:id/confirm", bookingHandler.ConfirmBooking)
 			bookings.POST("/:id/cancel", bookingHandler.CancelBooking)
+			bookings.POST("/:id/modify", bookingHandler.ModifyBooking)
+		}
+
+		// Gate check-in routes for venue staff
+		gate := middleware.GateAuth(cfg.App.GateAPIKey, cfg.App.AdminAPIKey)
+		v1.POST("/tickets/:id/checkin", gate, checkInHandler.CheckInTicket)
+		v1.POST("/checkin", gate, checkInHandler.CheckInBySeat)
+
+		// Series routes
+		v1.GET("/series/:id", eventHandler.GetSeries)
+
+		// A session's held seats across every event, for a multi-event cart
+		v1.GET("/holds", middleware.Pagination(cfg.App.DefaultPageSize, cfg.App.MaxPageSize, cfg.App.StrictPagination), eventHandler.ListSessionHolds)
+
+		// User routes; bulk cancellation is an operator action and needs the admin key
+		users := v1.Group("/users")
+		{
+			users.GET("/:id/events", middleware.Pagination(cfg.App.DefaultPageSize, cfg.App.MaxPageSize, cfg.App.StrictPagination), eventHandler.GetUserEvents)
+			users.POST("/:id/bookings/cancel-pending", middleware.AdminAuth(cfg.App.AdminAPIKey), adminHandler.CancelPendingBookings)
 		}
 	}
 
+	// Admin routes
+	admin := router.Group("/admin")
+	admin.Use(middleware.AdminAuth(cfg.App.AdminAPIKey))
+	{
+		admin.POST("/events/:id/reconcile", adminHandler.ReconcileAvailability)
+		admin.POST("/events/:id/adjust", adminHandler.AdjustAvailability)
+		admin.POST("/events/:id/tickets/rebuild", adminHandler.RebuildTickets)
+		admin.GET("/events/:id/locks", adminHandler.GetSeatLocks)
+		admin.POST("/events/:id/locks/clear", adminHandler.ClearSeatLocks)
+		admin.POST("/events/:id/seats/unlock-all", adminHandler.UnlockAllSeats)
+		admin.GET("/bookings", middleware.Pagination(cfg.App.DefaultPageSize, cfg.App.MaxPageSize, cfg.App.StrictPagination), adminHandler.ListBookings)
+		admin.POST("/bookings/:id/expire", adminHandler.ForceExpireBooking)
+	}
+
+	// Profiling routes, only when explicitly enabled
+	if cfg.App.EnablePprof {
+		logger.Warn("pprof endpoints enabled at /debug/pprof")
+		debug := router.Group("/debug/pprof")
+		debug.Use(middleware.AdminAuth(cfg.App.AdminAPIKey))
+		handlers.RegisterPprofRoutes(debug)
+	}
+
 	// 404 handler
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -158,21 +336,118 @@ func setupRouter(cfg *config.Config, logger *logrus.Logger, healthHandler *handl
 	return router
 }
 
-// startSeatLockCleanup runs a background routine to cleanup expired seat locks with configurable interval
-func startSeatLockCleanup(eventRepo *repository.EventRepository, logger *logrus.Logger, cleanupInterval time.Duration) {
-	ticker := time.NewTicker(cleanupInterval)
-	defer ticker.Stop()
+// rateLimiter selects the distributed limiter when requested and Redis is available,
+// falling back to the per-instance limiter otherwise
+func rateLimiter(cfg *config.Config, logger *logrus.Logger, redisClient *redis.Client) gin.HandlerFunc {
+	return middleware.RateLimitExempt(cfg.App.RateLimitExempt, rateLimitBackend(cfg, logger, redisClient))
+}
+
+func rateLimitBackend(cfg *config.Config, logger *logrus.Logger, redisClient *redis.Client) gin.HandlerFunc {
+	if cfg.App.RateLimitBackend == "redis" {
+		if redisClient != nil {
+			logger.Info("Using Redis rate limiter")
+			return middleware.RedisRateLimiter(redisClient, cfg.App.RateLimitRPS, logger)
+		}
+		logger.Warn("RATE_LIMIT_BACKEND=redis but REDIS_URL is not set, using in-memory rate limiter")
+	}
+	return middleware.RateLimiter(cfg.App.RateLimitRPS)
+}
+
+// Cleanup backoff: after a failed run the next one waits twice as long, up to
+// cleanupMaxBackoff intervals, so an overloaded database isn't hammered
+const (
+	cleanupFailureThreshold = 3
+	cleanupMaxBackoff       = 8
+)
+
+// startSeatLockCleanup runs a background routine to cleanup expired seat locks and holds with configurable interval
+func startSeatLockCleanup(ctx context.Context, eventRepo *repository.EventRepository, holdRepo *repository.HoldRepository, logger *logrus.Logger, cleanupInterval time.Duration, reconcile bool) {
+	timer := time.NewTimer(cleanupInterval)
+	defer timer.Stop()
 
 	logger.WithField("cleanup_interval", cleanupInterval).Info("Started seat lock cleanup routine")
 
+	failures := 0
 	for {
 		select {
-		case <-ticker.C:
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			if err := eventRepo.CleanupExpiredLocks(ctx); err != nil {
-				logger.WithError(err).Error("Failed to cleanup expired seat locks")
+		case <-ctx.Done():
+			logger.Info("Stopped seat lock cleanup routine")
+			return
+		case <-timer.C:
+		}
+
+		if runSeatLockCleanup(ctx, eventRepo, holdRepo, logger, reconcile) {
+			if failures >= cleanupFailureThreshold {
+				logger.WithField("consecutive_failures", failures).Info("Seat lock cleanup recovered")
 			}
-			cancel()
+			failures = 0
+		} else {
+			failures++
+		}
+
+		delay := cleanupInterval
+		for i := 0; i < failures && delay < cleanupInterval*cleanupMaxBackoff; i++ {
+			delay *= 2
+		}
+
+		if failures >= cleanupFailureThreshold {
+			// Expired locks are piling up and events may look sold out;
+			// alerting keys off this message and its fields
+			logger.WithFields(logrus.Fields{
+				"alert":                "seat_lock_cleanup_degraded",
+				"consecutive_failures": failures,
+				"next_run_in":          delay.String(),
+			}).Warn("Seat lock cleanup is failing repeatedly")
+		}
+
+		timer.Reset(delay)
+	}
+}
+
+// startPeriodicJob calls run every interval until ctx is cancelled. Each run
+// gets at most one interval to finish; failures are logged and retried on the
+// next tick.
+func startPeriodicJob(ctx context.Context, logger *logrus.Logger, name string, interval time.Duration, run func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.WithFields(logrus.Fields{"job": name, "interval": interval}).Info("Started " + name + " routine")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped " + name + " routine")
+			return
+		case <-ticker.C:
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, interval)
+		if err := run(runCtx); err != nil {
+			logger.WithError(err).WithField("job", name).Error("Periodic job failed")
+		}
+		cancel()
+	}
+}
+
+// runSeatLockCleanup performs one cleanup pass and reports whether every step succeeded
+func runSeatLockCleanup(ctx context.Context, eventRepo *repository.EventRepository, holdRepo *repository.HoldRepository, logger *logrus.Logger, reconcile bool) bool {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	ok := true
+	if err := eventRepo.CleanupExpiredLocks(ctx); err != nil {
+		logger.WithError(err).Error("Failed to cleanup expired seat locks")
+		ok = false
+	}
+	if err := holdRepo.CleanupExpiredHolds(ctx); err != nil {
+		logger.WithError(err).Error("Failed to cleanup expired holds")
+		ok = false
+	}
+	if reconcile {
+		if err := eventRepo.ReconcileAllAvailability(ctx); err != nil {
+			logger.WithError(err).Error("Failed to reconcile available tickets")
+			ok = false
 		}
 	}
+	return ok
 }
@@ -50,14 +50,14 @@ CREATE TABLE IF NOT EXISTS bookings (
 );
 
 -- Create indexes for better performance
-CREATE INDEX idx_events_start_time ON events(start_time);
-CREATE INDEX idx_events_available_tickets ON events(available_tickets);
-CREATE INDEX idx_tickets_event_id_status ON tickets(event_id, status);
-CREATE INDEX idx_bookings_user_id ON bookings(user_id);
-CREATE INDEX idx_bookings_event_id ON bookings(event_id);
-CREATE INDEX idx_bookings_status ON bookings(status);
-CREATE INDEX idx_bookings_expires_at ON bookings(expires_at);
-CREATE INDEX idx_bookings_booking_ref ON bookings(booking_ref);
+CREATE INDEX IF NOT EXISTS idx_events_start_time ON events(start_time);
+CREATE INDEX IF NOT EXISTS idx_events_available_tickets ON events(available_tickets);
+CREATE INDEX IF NOT EXISTS idx_tickets_event_id_status ON tickets(event_id, status);
+CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON bookings(user_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_event_id ON bookings(event_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_status ON bookings(status);
+CREATE INDEX IF NOT EXISTS idx_bookings_expires_at ON bookings(expires_at);
+CREATE INDEX IF NOT EXISTS idx_bookings_booking_ref ON bookings(booking_ref);
 
 -- Create trigger function to update updated_at timestamp
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -69,14 +69,18 @@ END;
 $$ language 'plpgsql';
 
 -- Create triggers for automatic updated_at updates
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
 CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+DROP TRIGGER IF EXISTS update_events_updated_at ON events;
 CREATE TRIGGER update_events_updated_at BEFORE UPDATE ON events
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+DROP TRIGGER IF EXISTS update_tickets_updated_at ON tickets;
 CREATE TRIGGER update_tickets_updated_at BEFORE UPDATE ON tickets
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+DROP TRIGGER IF EXISTS update_bookings_updated_at ON bookings;
 CREATE TRIGGER update_bookings_updated_at BEFORE UPDATE ON bookings
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column(); 
\ No newline at end of file
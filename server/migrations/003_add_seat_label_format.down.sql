@@ -0,0 +1,3 @@
+-- Remove configurable seat labelling scheme from events
+ALTER TABLE events DROP COLUMN IF EXISTS seat_rows;
+ALTER TABLE events DROP COLUMN IF EXISTS seat_label_format;
@@ -0,0 +1,3 @@
+-- Add configurable seat labelling scheme to events
+ALTER TABLE events ADD COLUMN IF NOT EXISTS seat_label_format VARCHAR(50);
+ALTER TABLE events ADD COLUMN IF NOT EXISTS seat_rows INTEGER CHECK (seat_rows IS NULL OR seat_rows > 0);
@@ -0,0 +1,6 @@
+-- Drop holds
+DROP TRIGGER IF EXISTS update_holds_updated_at ON holds;
+DROP INDEX IF EXISTS idx_tickets_hold_id;
+DROP INDEX IF EXISTS idx_holds_status_expires_at;
+ALTER TABLE tickets DROP COLUMN IF EXISTS hold_id;
+DROP TABLE IF EXISTS holds;
@@ -0,0 +1,20 @@
+-- Create holds table for transient seat selections
+CREATE TABLE IF NOT EXISTS holds (
+    id VARCHAR(64) PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    session_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'converted', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Link held tickets to their hold
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS hold_id VARCHAR(64) REFERENCES holds(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_holds_status_expires_at ON holds(status, expires_at);
+CREATE INDEX IF NOT EXISTS idx_tickets_hold_id ON tickets(hold_id);
+
+DROP TRIGGER IF EXISTS update_holds_updated_at ON holds;
+CREATE TRIGGER update_holds_updated_at BEFORE UPDATE ON holds
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
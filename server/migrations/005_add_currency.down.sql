@@ -0,0 +1,3 @@
+-- Remove currency from events and bookings
+ALTER TABLE bookings DROP COLUMN IF EXISTS currency;
+ALTER TABLE events DROP COLUMN IF EXISTS currency;
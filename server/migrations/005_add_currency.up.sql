@@ -0,0 +1,3 @@
+-- Add ISO 4217 currency to events and bookings; amounts stay DECIMAL(10,2)
+ALTER TABLE events ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
@@ -0,0 +1,2 @@
+-- Remove per-event cap on tickets per booking
+ALTER TABLE events DROP COLUMN IF EXISTS max_per_booking;
@@ -0,0 +1,2 @@
+-- Add optional per-event cap on tickets per booking
+ALTER TABLE events ADD COLUMN IF NOT EXISTS max_per_booking INTEGER CHECK (max_per_booking IS NULL OR max_per_booking > 0);
@@ -0,0 +1,3 @@
+-- Remove per-event cap on tickets per user
+DROP INDEX IF EXISTS idx_bookings_user_id_event_id;
+ALTER TABLE events DROP COLUMN IF EXISTS max_per_user;
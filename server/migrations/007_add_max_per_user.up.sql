@@ -0,0 +1,3 @@
+-- Add optional per-event cap on tickets per user
+ALTER TABLE events ADD COLUMN IF NOT EXISTS max_per_user INTEGER CHECK (max_per_user IS NULL OR max_per_user > 0);
+CREATE INDEX IF NOT EXISTS idx_bookings_user_id_event_id ON bookings(user_id, event_id);
@@ -0,0 +1,3 @@
+-- Remove seat lock ownership
+DROP INDEX IF EXISTS idx_tickets_event_id_locked_by;
+ALTER TABLE tickets DROP COLUMN IF EXISTS locked_by;
@@ -0,0 +1,3 @@
+-- Record which session holds a seat lock
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS locked_by VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_tickets_event_id_locked_by ON tickets(event_id, locked_by) WHERE locked_by IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Remove external event references
+DROP INDEX IF EXISTS idx_events_external_ref;
+ALTER TABLE events DROP COLUMN IF EXISTS external_ref;
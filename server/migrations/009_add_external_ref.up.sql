@@ -0,0 +1,3 @@
+-- Caller-supplied identifier that makes event creation idempotent
+ALTER TABLE events ADD COLUMN IF NOT EXISTS external_ref VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_external_ref ON events(external_ref);
@@ -0,0 +1,2 @@
+-- Remove the sales close offset
+ALTER TABLE events DROP COLUMN IF EXISTS sales_close_offset;
@@ -0,0 +1,2 @@
+-- Stop sales a fixed number of seconds before the event starts (0 = at start)
+ALTER TABLE events ADD COLUMN IF NOT EXISTS sales_close_offset INTEGER NOT NULL DEFAULT 0 CHECK (sales_close_offset >= 0);
@@ -0,0 +1,4 @@
+-- Remove coupons
+ALTER TABLE bookings DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE bookings DROP COLUMN IF EXISTS coupon_code;
+DROP TABLE IF EXISTS coupons;
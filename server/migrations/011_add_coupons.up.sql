@@ -0,0 +1,25 @@
+-- Promo codes that discount a booking's total
+CREATE TABLE IF NOT EXISTS coupons (
+    code VARCHAR(64) PRIMARY KEY,
+    percent_off INTEGER CHECK (percent_off BETWEEN 1 AND 100),
+    amount_off DECIMAL(10,2) CHECK (amount_off > 0),
+    currency CHAR(3),
+    event_id INTEGER REFERENCES events(id) ON DELETE CASCADE, -- NULL: valid for every event
+    max_uses INTEGER CHECK (max_uses IS NULL OR max_uses > 0), -- NULL: unlimited
+    times_used INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    -- Exactly one kind of discount; fixed amounts are in a specific currency
+    CHECK ((percent_off IS NULL) <> (amount_off IS NULL)),
+    CHECK (amount_off IS NULL OR currency IS NOT NULL)
+);
+
+DROP TRIGGER IF EXISTS update_coupons_updated_at ON coupons;
+CREATE TRIGGER update_coupons_updated_at BEFORE UPDATE ON coupons
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Record the coupon applied to a booking and how much it took off
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS coupon_code VARCHAR(64) REFERENCES coupons(code);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Remove recurring event series
+DROP INDEX IF EXISTS idx_events_series_id;
+ALTER TABLE events DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS event_series;
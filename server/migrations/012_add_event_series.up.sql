@@ -0,0 +1,15 @@
+-- Recurring events: occurrences created together share a series
+CREATE TABLE IF NOT EXISTS event_series (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+DROP TRIGGER IF EXISTS update_event_series_updated_at ON event_series;
+CREATE TRIGGER update_event_series_updated_at BEFORE UPDATE ON event_series
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES event_series(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_events_series_id ON events(series_id);
@@ -0,0 +1,2 @@
+-- Remove the per-event seat lock duration
+ALTER TABLE events DROP COLUMN IF EXISTS seat_lock_duration;
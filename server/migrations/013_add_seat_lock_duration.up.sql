@@ -0,0 +1,2 @@
+-- Per-event seat lock duration in seconds; NULL falls back to SEAT_LOCK_DURATION
+ALTER TABLE events ADD COLUMN IF NOT EXISTS seat_lock_duration INTEGER CHECK (seat_lock_duration > 0);
@@ -0,0 +1,2 @@
+-- Remove the original seat lock time
+ALTER TABLE tickets DROP COLUMN IF EXISTS locked_at;
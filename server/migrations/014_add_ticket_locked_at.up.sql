@@ -0,0 +1,2 @@
+-- Record when a seat lock was first taken; refreshes leave it unchanged
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP WITH TIME ZONE;
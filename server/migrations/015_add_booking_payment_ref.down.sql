@@ -0,0 +1,3 @@
+-- Remove the booking payment reference
+DROP INDEX IF EXISTS idx_bookings_payment_ref;
+ALTER TABLE bookings DROP COLUMN IF EXISTS payment_ref;
@@ -0,0 +1,3 @@
+-- Record the payment that confirmed a booking; one payment confirms one booking
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS payment_ref VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_payment_ref ON bookings(payment_ref) WHERE payment_ref IS NOT NULL;
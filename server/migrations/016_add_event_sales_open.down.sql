@@ -0,0 +1,2 @@
+-- Remove the sales pause flag
+ALTER TABLE events DROP COLUMN IF EXISTS is_sales_open;
@@ -0,0 +1,2 @@
+-- Organizers can pause sales without deleting the event; reads are unaffected
+ALTER TABLE events ADD COLUMN IF NOT EXISTS is_sales_open BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,8 @@
+-- Remove guest contact details; guest bookings have no user and are deleted
+DROP INDEX IF EXISTS idx_bookings_guest_email;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_buyer_check;
+DELETE FROM bookings WHERE user_id IS NULL;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_phone;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_email;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_name;
+ALTER TABLE bookings ALTER COLUMN user_id SET NOT NULL;
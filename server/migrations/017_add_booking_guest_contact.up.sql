@@ -0,0 +1,8 @@
+-- Guest bookings carry contact details instead of a user; every booking has one or the other
+ALTER TABLE bookings ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guest_name VARCHAR(255);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guest_email VARCHAR(255);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guest_phone VARCHAR(20);
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_buyer_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_buyer_check CHECK (user_id IS NOT NULL OR guest_email IS NOT NULL);
+CREATE INDEX IF NOT EXISTS idx_bookings_guest_email ON bookings (lower(guest_email)) WHERE user_id IS NULL;
@@ -0,0 +1,3 @@
+-- Remove gate check-in
+ALTER TABLE tickets DROP COLUMN IF EXISTS checked_in_by;
+ALTER TABLE tickets DROP COLUMN IF EXISTS checked_in_at;
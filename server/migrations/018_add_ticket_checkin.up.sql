@@ -0,0 +1,3 @@
+-- Gate check-in: set once when a sold ticket is scanned at entry
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS checked_in_by VARCHAR(255);
@@ -0,0 +1,2 @@
+-- Remove the cross-event session lock index
+DROP INDEX IF EXISTS idx_tickets_locked_by;
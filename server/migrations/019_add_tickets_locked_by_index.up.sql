@@ -0,0 +1,2 @@
+-- Look up a session's locks across every event, for the global cart
+CREATE INDEX IF NOT EXISTS idx_tickets_locked_by ON tickets(locked_by) WHERE locked_by IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Remove event images
+ALTER TABLE events DROP COLUMN IF EXISTS banner_url;
+ALTER TABLE events DROP COLUMN IF EXISTS image_url;
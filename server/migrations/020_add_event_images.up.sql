@@ -0,0 +1,3 @@
+-- Optional poster and banner images shown by the UI
+ALTER TABLE events ADD COLUMN IF NOT EXISTS image_url TEXT;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS banner_url TEXT;
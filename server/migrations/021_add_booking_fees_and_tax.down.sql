@@ -0,0 +1,4 @@
+-- Remove itemised booking totals
+ALTER TABLE bookings DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE bookings DROP COLUMN IF EXISTS fees_amount;
+ALTER TABLE bookings DROP COLUMN IF EXISTS subtotal;
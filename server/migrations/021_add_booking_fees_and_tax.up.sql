@@ -0,0 +1,7 @@
+-- Itemise booking totals: total_amount = subtotal - discount_amount + fees_amount + tax_amount
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS subtotal DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS fees_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Earlier bookings carried no fees or tax
+UPDATE bookings SET subtotal = total_amount + discount_amount WHERE subtotal = 0;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL schema migrations so the server can
+// apply them on startup without psql or a separate migration tool.
+package migrations
+
+import "embed"
+
+// FS holds every NNN_name.up.sql and NNN_name.down.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -45,6 +45,18 @@ export interface ApiResponse<T> {
   message?: string;
 }
 
+// One session per browser tab, so the server can tell our seat locks apart
+// from everyone else's and let us unlock and book them
+function getSessionId(): string {
+  const key = 'ticket_session_id';
+  let sessionId = sessionStorage.getItem(key);
+  if (!sessionId) {
+    sessionId = `session_${Date.now()}_${Math.random().toString(36).slice(2)}`;
+    sessionStorage.setItem(key, sessionId);
+  }
+  return sessionId;
+}
+
 class ApiService {
   private async request<T>(endpoint: string, options?: RequestInit): Promise<ApiResponse<T>> {
     try {
@@ -96,6 +108,10 @@ class ApiService {
   async bookTickets(booking: BookingRequest): Promise<ApiResponse<BookingResponse>> {
     return this.request<BookingResponse>('/bookings', {
       method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        'X-Session-ID': getSessionId(),
+      },
       body: JSON.stringify(booking),
     });
   }
@@ -120,7 +136,7 @@ class ApiService {
     return this.request(`/events/${eventId}/seats/${seatNo}/lock`, {
       method: 'POST',
       headers: {
-        'X-Session-ID': `session_${Date.now()}_${Math.random()}`, // Generate session ID
+        'X-Session-ID': getSessionId(),
       },
     });
   }
@@ -128,6 +144,9 @@ class ApiService {
   async unlockSeat(eventId: number, seatNo: string): Promise<ApiResponse<any>> {
     return this.request(`/events/${eventId}/seats/${seatNo}/unlock`, {
       method: 'POST',
+      headers: {
+        'X-Session-ID': getSessionId(),
+      },
     });
   }
 }